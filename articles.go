@@ -0,0 +1,448 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxArticleSize     = 5 << 20
+	minParagraphLength = 25
+
+	// articleWorkers bounds the pages downloaded at once after a feed is
+	// fetched, and articleTimeout the time spent on each of them.
+	articleWorkers = 4
+	articleTimeout = 30 * time.Second
+)
+
+var (
+	// unlikelyPattern matches the class and id of page chrome that never
+	// holds the article, unless maybePattern matches as well.
+	unlikelyPattern = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|header|legends|menu|modal|nav|popup|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|tweet|widget`)
+	maybePattern    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	positivePattern = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|story|text|blog`)
+	negativePattern = regexp.MustCompile(`(?i)hidden|banner|combx|comment|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget|ad-`)
+
+	// chromeElements are removed before scoring the page.
+	chromeElements = map[atom.Atom]bool{
+		atom.Aside:    true,
+		atom.Button:   true,
+		atom.Footer:   true,
+		atom.Form:     true,
+		atom.Header:   true,
+		atom.Iframe:   true,
+		atom.Nav:      true,
+		atom.Noscript: true,
+		atom.Script:   true,
+		atom.Style:    true,
+		atom.Svg:      true,
+	}
+)
+
+func handlerFullText(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 3 || (cmd.args[2] != "on" && cmd.args[2] != "off") {
+		return errors.New("the command fulltext expect two arguments: <feed> on|off")
+	}
+
+	candidates, err := s.followedFeedCandidates(user)
+	if err != nil {
+		return err
+	}
+
+	feed, err := s.resolveFeed(cmd.args[1], candidates)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.db.SetFeedFollowFetchFullContent(context.Background(),
+		database.SetFeedFollowFetchFullContentParams{
+			FetchFullContent: cmd.args[2] == "on",
+			UserID:           user.ID,
+			FeedID:           feed.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the feed follow could not be updated: %v", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("you are not following %s", feed.Url)
+	}
+
+	fmt.Printf("Full articles of %s: %s\n", feed.Url, cmd.args[2])
+	return nil
+}
+
+// articleJob is a new post whose article is fetched once its feed is done.
+type articleJob struct {
+	postID uuid.UUID
+	link   string
+}
+
+// storeFullContents fetches the articles of new posts with a bounded number
+// of workers, so that a slow site holds up neither the other pages nor the
+// next feed for long.
+func (s *state) storeFullContents(jobs []articleJob) {
+	queue := make(chan articleJob)
+	var wg sync.WaitGroup
+	for range min(articleWorkers, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				s.storeFullContent(job.postID, job.link)
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// storeFullContent extracts the article of a new post and stores it next
+// to the description. Failures are reported and leave the post as it is.
+func (s *state) storeFullContent(postID uuid.UUID, link string) {
+	ctx, cancel := context.WithTimeout(context.Background(), articleTimeout)
+	defer cancel()
+
+	content, canonical, err := fetchArticle(ctx, link)
+	if canonical != "" && !s.canonicalizePost(postID, link, canonical) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("The article of %s could not be extracted: %v\n", link, err)
+		return
+	}
+
+	err = s.db.SetPostFullContent(context.Background(),
+		database.SetPostFullContentParams{
+			ID:          postID,
+			FullContent: sql.NullString{String: content, Valid: true},
+		},
+	)
+
+	if err != nil {
+		fmt.Printf("The article of %s could not be stored: %v\n", link, err)
+	}
+}
+
+// canonicalizePost moves a new post to the canonical URL of its page. A post
+// already stored under that URL is a duplicate: the new one is deleted and
+// false is returned. Either way the link of the feed is kept as an alias of
+// the canonical post so that it is not ingested again.
+func (s *state) canonicalizePost(postID uuid.UUID, link, canonical string) bool {
+	canonical = s.normalizeLink(canonical)
+	if canonical == link {
+		return true
+	}
+
+	err := s.db.SetPostURL(context.Background(),
+		database.SetPostURLParams{
+			ID:  postID,
+			Url: canonical,
+		},
+	)
+
+	duplicate := isUniqueViolation(err)
+	if duplicate {
+		if err := s.db.DeletePost(context.Background(), postID); err != nil {
+			fmt.Printf("The duplicate post %s could not be deleted: %v\n", link, err)
+			return true
+		}
+		fmt.Printf("The post %s is a duplicate of %s\n", link, canonical)
+	} else if err != nil {
+		fmt.Printf("The canonical URL of %s could not be stored: %v\n", link, err)
+		return true
+	}
+
+	err = s.db.AddPostLink(context.Background(),
+		database.AddPostLinkParams{
+			Link: link,
+			Url:  canonical,
+		},
+	)
+
+	if err != nil {
+		fmt.Printf("The link %s could not be kept for %s: %v\n", link, canonical, err)
+	}
+	return !duplicate
+}
+
+// fetchArticle downloads the page of a post and extracts its main content.
+// The canonical URL of the page is returned even when no article is found.
+func fetchArticle(ctx context.Context, link string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("the request failed: %v", err)
+	}
+
+	req.Header.Set("User-Agent", "gator")
+
+	client := http.Client{Timeout: 30 * time.Second}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("the page answered %s", res.Status)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return "", "", fmt.Errorf("the page is not HTML but %s", mediaType)
+	}
+
+	doc, err := html.Parse(io.LimitReader(res.Body, maxArticleSize))
+	if err != nil {
+		return "", "", fmt.Errorf("the page could not be parsed: %v", err)
+	}
+
+	canonical := canonicalLink(doc, link)
+	content, err := articleContent(doc, link)
+	return content, canonical, err
+}
+
+// canonicalLink returns the URL of the <link rel="canonical"> of a page,
+// resolved against the URL of the page. Canonical URLs of another site or
+// of the home page, as set by misconfigured templates, are ignored.
+func canonicalLink(doc *html.Node, link string) string {
+	base, err := url.Parse(link)
+	if err != nil || !base.IsAbs() {
+		base = nil
+	}
+
+	for _, n := range elements(doc) {
+		if n.DataAtom != atom.Link {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.ToLower(attr(n, "rel"))) {
+			if rel != "canonical" {
+				continue
+			}
+			if canonical, ok := sanitizeURL(attr(n, "href"), base, false); ok && isArticleOf(canonical, base) {
+				return canonical
+			}
+		}
+	}
+	return ""
+}
+
+// isArticleOf reports whether a canonical URL names a page of the site of
+// the post other than its home page.
+func isArticleOf(canonical string, page *url.URL) bool {
+	u, err := url.Parse(canonical)
+	if err != nil || page == nil {
+		return false
+	}
+
+	site := func(host string) string {
+		return strings.TrimPrefix(strings.ToLower(host), "www.")
+	}
+	return site(u.Host) == site(page.Host) && strings.Trim(u.Path, "/") != ""
+}
+
+// extractArticle finds the main content of a page in the manner of
+// readability: the paragraphs score their parents by length and commas, the
+// class and id of the candidates make them more or less likely, and links
+// lower the score. The content of the best candidate and of its siblings
+// with a close score is returned sanitized.
+func extractArticle(page io.Reader, link string) (string, error) {
+	doc, err := html.Parse(page)
+	if err != nil {
+		return "", fmt.Errorf("the page could not be parsed: %v", err)
+	}
+	return articleContent(doc, link)
+}
+
+// articleContent extracts the article of a parsed page, see extractArticle.
+func articleContent(doc *html.Node, link string) (string, error) {
+	removeChrome(doc)
+
+	scores := map[*html.Node]float64{}
+	candidates := []*html.Node{}
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+
+	for _, n := range elements(doc) {
+		if n.DataAtom != atom.P && n.DataAtom != atom.Pre && n.DataAtom != atom.Td && !isTextDiv(n) {
+			continue
+		}
+
+		text := textContent(n)
+		if utf8.RuneCountInString(text) < minParagraphLength {
+			continue
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(utf8.RuneCountInString(text))/100, 3)
+		addScore(n.Parent, score)
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+	}
+
+	var best *html.Node
+	for _, n := range candidates {
+		scores[n] *= 1 - linkDensity(n)
+		if best == nil || scores[n] > scores[best] {
+			best = n
+		}
+	}
+	if best == nil {
+		return "", errors.New("no article was found in the page")
+	}
+
+	threshold := max(10, scores[best]*0.2)
+	var article strings.Builder
+	for sibling := best.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		score, scored := scores[sibling]
+		if sibling == best || (scored && score >= threshold) {
+			html.Render(&article, sibling)
+		}
+	}
+
+	content := sanitizeHTML(article.String(), link)
+	if strings.TrimSpace(stripHTML(content)) == "" {
+		return "", errors.New("no article was found in the page")
+	}
+	return content, nil
+}
+
+// removeChrome removes the elements that cannot hold the article.
+func removeChrome(doc *html.Node) {
+	for _, n := range elements(doc) {
+		if n.Parent == nil || n.DataAtom == atom.Body || n.DataAtom == atom.Html || n.DataAtom == atom.Article {
+			continue
+		}
+
+		hint := attr(n, "class") + " " + attr(n, "id")
+		if chromeElements[n.DataAtom] || (unlikelyPattern.MatchString(hint) && !maybePattern.MatchString(hint)) {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+// initialScore weighs a candidate by its element and its class and id.
+func initialScore(n *html.Node) float64 {
+	score := 0.0
+	switch n.DataAtom {
+	case atom.Article:
+		score += 10
+	case atom.Div, atom.Section, atom.Main:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+
+	for _, hint := range []string{attr(n, "class"), attr(n, "id")} {
+		if hint == "" {
+			continue
+		}
+		if negativePattern.MatchString(hint) {
+			score -= 25
+		}
+		if positivePattern.MatchString(hint) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// isTextDiv reports whether a div holds text directly instead of blocks.
+func isTextDiv(n *html.Node) bool {
+	if n.DataAtom != atom.Div {
+		return false
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch child.DataAtom {
+		case atom.P, atom.Div, atom.Table, atom.Ul, atom.Ol, atom.Pre, atom.Blockquote, atom.Section, atom.Article:
+			return false
+		}
+	}
+	return true
+}
+
+// linkDensity is the share of the text of n that is inside links.
+func linkDensity(n *html.Node) float64 {
+	length := utf8.RuneCountInString(textContent(n))
+	if length == 0 {
+		return 0
+	}
+
+	linked := 0
+	for _, a := range elements(n) {
+		if a.DataAtom == atom.A {
+			linked += utf8.RuneCountInString(textContent(a))
+		}
+	}
+	return float64(linked) / float64(length)
+}
+
+// elements lists the elements below n in document order. The list is built
+// before returning so that callers may remove nodes while iterating.
+func elements(n *html.Node) []*html.Node {
+	nodes := []*html.Node{}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode {
+				nodes = append(nodes, child)
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+	return nodes
+}
+
+// textContent returns the text below n with its whitespace collapsed.
+func textContent(n *html.Node) string {
+	var text strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			text.WriteString(n.Data)
+			text.WriteString(" ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(text.String()), " ")
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head><title>Release notes</title><script>track()</script></head>
+<body>
+  <nav class="menu"><a href="/">Home</a> <a href="/blog">Blog</a> <a href="/about">About us and our long story</a></nav>
+  <div class="sidebar"><p>Subscribe to our newsletter, it is great, really, trust us.</p></div>
+  <div class="post-content">
+    <h1>Go 1.24 is released</h1>
+    <p>The Go team is happy to announce the release of Go 1.24, with generic type aliases, faster maps and a new weak package.</p>
+    <p>As always, the release keeps the Go 1 promise of compatibility, so almost every program should keep working.</p>
+    <p>Read the <a href="/doc/go1.24">release notes</a> for the details of every change, including tooling.</p>
+  </div>
+  <div id="comments"><p>Great release, thanks a lot to everybody involved in it!</p></div>
+  <footer><p>Copyright, the Go authors, all rights reserved, since forever.</p></footer>
+</body>
+</html>`
+
+func TestExtractArticle(t *testing.T) {
+	content, err := extractArticle(strings.NewReader(articlePage), "https://go.dev/blog/go1.24")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"announce the release of Go 1.24",
+		"Go 1 promise of compatibility",
+		`href="https://go.dev/doc/go1.24"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("the article lacks %q:\n%s", want, content)
+		}
+	}
+
+	for _, unwanted := range []string{"newsletter", "Great release", "Copyright", "track()", "About us"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("the article contains %q:\n%s", unwanted, content)
+		}
+	}
+}
+
+func TestExtractArticleWithoutContent(t *testing.T) {
+	_, err := extractArticle(strings.NewReader("<html><body><nav>Menu</nav></body></html>"), "https://example.com")
+	if err == nil {
+		t.Errorf("expected an error for a page without article")
+	}
+}
+
+func TestCanonicalLink(t *testing.T) {
+	cases := []struct {
+		name string
+		page string
+		want string
+	}{
+		{
+			name: "Absolute",
+			page: `<html><head><link rel="canonical" href="https://example.com/post"></head></html>`,
+			want: "https://example.com/post",
+		},
+		{
+			name: "Relative",
+			page: `<html><head><link rel="Canonical" href="/2024/post"></head></html>`,
+			want: "https://example.com/2024/post",
+		},
+		{
+			name: "Several rel values",
+			page: `<html><head><link rel="alternate canonical" href="https://example.com/a"></head></html>`,
+			want: "https://example.com/a",
+		},
+		{
+			name: "Not a web URL",
+			page: `<html><head><link rel="canonical" href="javascript:alert(1)"></head></html>`,
+			want: "",
+		},
+		{
+			name: "Missing",
+			page: `<html><head><link rel="stylesheet" href="/style.css"></head></html>`,
+			want: "",
+		},
+		{
+			name: "Other site",
+			page: `<html><head><link rel="canonical" href="https://aggregator.example.org/post"></head></html>`,
+			want: "",
+		},
+		{
+			name: "Same site with www",
+			page: `<html><head><link rel="canonical" href="https://www.example.com/post"></head></html>`,
+			want: "https://www.example.com/post",
+		},
+		{
+			name: "Home page",
+			page: `<html><head><link rel="canonical" href="https://example.com/"></head></html>`,
+			want: "",
+		},
+		{
+			name: "Home page without slash",
+			page: `<html><head><link rel="canonical" href="https://example.com"></head></html>`,
+			want: "",
+		},
+	}
+
+	for _, c := range cases {
+		doc, err := html.Parse(strings.NewReader(c.page))
+		if err != nil {
+			t.Fatalf("%s: the page could not be parsed: %v", c.name, err)
+		}
+		if got := canonicalLink(doc, "https://example.com/feed/item?id=1"); got != c.want {
+			t.Errorf("%s: canonicalLink() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
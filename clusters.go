@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"hash/fnv"
+	"math/bits"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const (
+	// clusterMaxDistance is the largest number of differing SimHash bits
+	// for two posts to be considered the same story.
+	clusterMaxDistance = 4
+
+	// clusterWindow is how far back the aggregator looks for posts of the
+	// same story.
+	clusterWindow = 48 * time.Hour
+
+	// titleWeight makes the words of the title count more than the ones of
+	// the description, which vary a lot more between feeds.
+	titleWeight = 3
+)
+
+// simhash computes the 64 bit SimHash of a post from the words and word
+// pairs of its title and description. Similar texts produce hashes that
+// differ in few bits.
+func simhash(title, description string) uint64 {
+	weights := [64]int{}
+
+	addFeatures := func(tokens []string, weight int) {
+		for i, token := range tokens {
+			features := []string{token}
+			if i > 0 {
+				features = append(features, tokens[i-1]+" "+token)
+			}
+
+			for _, feature := range features {
+				h := fnv.New64a()
+				h.Write([]byte(feature))
+				sum := h.Sum64()
+				for bit := range weights {
+					if sum&(1<<bit) != 0 {
+						weights[bit] += weight
+					} else {
+						weights[bit] -= weight
+					}
+				}
+			}
+		}
+	}
+
+	addFeatures(tokenize(title), titleWeight)
+	addFeatures(tokenize(description), 1)
+
+	var hash uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash
+}
+
+// closestCandidate returns the candidate closest to a post with the given
+// hash, if any is close enough. Clusters already holding a post of the feed
+// of the post are skipped, so that a cluster groups one post per feed.
+func closestCandidate(hash uint64, candidates []database.GetClusterCandidatesRow) (database.GetClusterCandidatesRow, bool) {
+	bestDistance := clusterMaxDistance + 1
+	var best database.GetClusterCandidatesRow
+	for _, candidate := range candidates {
+		if candidate.ClusterHasFeed {
+			continue
+		}
+		distance := hammingDistance(hash, uint64(candidate.Simhash.Int64))
+		if distance < bestDistance {
+			bestDistance, best = distance, candidate
+		}
+	}
+	return best, bestDistance <= clusterMaxDistance
+}
+
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// clusterPost groups a newly ingested post with the closest recent post of
+// another feed telling the same story, creating the cluster when needed.
+func (s *state) clusterPost(postID, feedID uuid.UUID, hash uint64) error {
+	candidates, err := s.db.GetClusterCandidates(context.Background(),
+		database.GetClusterCandidatesParams{
+			Since:  time.Now().Add(-clusterWindow),
+			PostID: postID,
+			FeedID: feedID,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	best, found := closestCandidate(hash, candidates)
+	if !found {
+		return nil
+	}
+
+	clusterID := best.ClusterID
+	if clusterID.Valid {
+		if err := s.db.TouchCluster(context.Background(), clusterID.UUID); err != nil {
+			return err
+		}
+	} else {
+		cluster, err := s.db.CreateCluster(context.Background(), uuid.New())
+
+		if err != nil {
+			return err
+		}
+
+		clusterID = uuid.NullUUID{UUID: cluster.ID, Valid: true}
+		err = s.db.SetPostCluster(context.Background(),
+			database.SetPostClusterParams{
+				ClusterID: clusterID,
+				PostID:    best.ID,
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.db.SetPostCluster(context.Background(),
+		database.SetPostClusterParams{
+			ClusterID: clusterID,
+			PostID:    postID,
+		},
+	)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+func TestSimhash(t *testing.T) {
+	story := simhash(
+		"Apple unveils the new iPhone 17 with a faster chip",
+		"Apple announced the iPhone 17 today at its September event in Cupertino.",
+	)
+	sameStory := simhash(
+		"Apple unveils the new iPhone 17 with a faster chip",
+		"Apple announced the iPhone 17 today at its event in Cupertino.",
+	)
+	otherStory := simhash(
+		"Rust 1.80 stabilizes lazy cells",
+		"The Rust team released version 1.80 of the compiler with LazyCell and LazyLock.",
+	)
+
+	if d := hammingDistance(story, story); d != 0 {
+		t.Errorf("distance of a post with itself = %d, want 0", d)
+	}
+
+	if d := hammingDistance(story, sameStory); d > clusterMaxDistance {
+		t.Errorf("distance between versions of the same story = %d, want <= %d", d, clusterMaxDistance)
+	}
+
+	if d := hammingDistance(story, otherStory); d <= clusterMaxDistance {
+		t.Errorf("distance between different stories = %d, want > %d", d, clusterMaxDistance)
+	}
+}
+
+func TestClosestCandidate(t *testing.T) {
+	hash := uint64(0xF0F0)
+	candidate := func(id string, distance int, hasFeed bool) database.GetClusterCandidatesRow {
+		return database.GetClusterCandidatesRow{
+			ID:             uuid.MustParse(id),
+			Simhash:        sql.NullInt64{Int64: int64(hash ^ (1<<distance - 1)), Valid: true},
+			ClusterHasFeed: hasFeed,
+		}
+	}
+
+	sameFeed := candidate("00000000-0000-4000-8000-000000000001", 1, true)
+	otherFeed := candidate("00000000-0000-4000-8000-000000000002", 3, false)
+	tooFar := candidate("00000000-0000-4000-8000-000000000003", clusterMaxDistance+1, false)
+
+	cases := []struct {
+		name       string
+		candidates []database.GetClusterCandidatesRow
+		want       uuid.UUID
+		found      bool
+	}{
+		{name: "No candidates"},
+		{name: "Closest", candidates: []database.GetClusterCandidatesRow{tooFar, otherFeed}, want: otherFeed.ID, found: true},
+		{name: "Cluster with a post of the feed", candidates: []database.GetClusterCandidatesRow{sameFeed, otherFeed}, want: otherFeed.ID, found: true},
+		{name: "Only clusters with a post of the feed", candidates: []database.GetClusterCandidatesRow{sameFeed}},
+		{name: "Too far", candidates: []database.GetClusterCandidatesRow{tooFar}},
+	}
+
+	for _, c := range cases {
+		got, found := closestCandidate(hash, c.candidates)
+		if found != c.found || (found && got.ID != c.want) {
+			t.Errorf("%s: closestCandidate() = %s, %t, want %s, %t", c.name, got.ID, found, c.want, c.found)
+		}
+	}
+}
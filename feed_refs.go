@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const maxFeedSuggestions = 3
+
+// feedCandidate is a feed a command argument may refer to, with the names
+// it is known by.
+type feedCandidate struct {
+	feed  database.Feed
+	names []string
+}
+
+// feedNotFoundError reports a feed reference matching no feed, with the
+// names of the closest feeds.
+type feedNotFoundError struct {
+	ref         string
+	suggestions []string
+}
+
+func (e *feedNotFoundError) Error() string {
+	if len(e.suggestions) == 0 {
+		return fmt.Sprintf("no feed matches %s, run feeds to list them", e.ref)
+	}
+	return fmt.Sprintf("no feed matches %s, did you mean %s?", e.ref, strings.Join(e.suggestions, ", "))
+}
+
+// allFeedCandidates lists every feed under its name.
+func (s *state) allFeedCandidates() ([]feedCandidate, error) {
+	feeds, err := s.db.GetAllFeeds(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("the feeds could not be loaded: %v", err)
+	}
+
+	candidates := make([]feedCandidate, 0, len(feeds))
+	for _, feed := range feeds {
+		candidates = append(candidates, feedCandidate{feed: feed, names: []string{feed.Name}})
+	}
+	return candidates, nil
+}
+
+// followedFeedCandidates lists the feeds followed by the user under the name
+// the user gave them and their own name.
+func (s *state) followedFeedCandidates(user database.User) ([]feedCandidate, error) {
+	feeds, err := s.db.GetFollowedFeeds(context.Background(), user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("the followed feeds could not be loaded: %v", err)
+	}
+
+	candidates := make([]feedCandidate, 0, len(feeds))
+	for _, row := range feeds {
+		candidate := feedCandidate{
+			feed: database.Feed{
+				ID:            row.ID,
+				CreatedAt:     row.CreatedAt,
+				UpdatedAt:     row.UpdatedAt,
+				Name:          row.Name,
+				Url:           row.Url,
+				UserID:        row.UserID,
+				LastFetchedAt: row.LastFetchedAt,
+				PausedAt:      row.PausedAt,
+			},
+			names: []string{row.FollowName},
+		}
+		if row.Name != row.FollowName {
+			candidate.names = append(candidate.names, row.Name)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// resolveFeed finds the feed referenced by a command argument among the
+// candidates. When several feeds match, the user picks one.
+func (s *state) resolveFeed(ref string, candidates []feedCandidate) (database.Feed, error) {
+	match, _, err := s.resolveCandidate(ref, candidates)
+	return match.feed, err
+}
+
+// resolveFeedToChange is resolveFeed for the commands that unfollow or pause
+// a feed: a feed found only by a part of its name or URL is shown and must be
+// confirmed first.
+func (s *state) resolveFeedToChange(ref string, candidates []feedCandidate) (database.Feed, error) {
+	match, sure, err := s.resolveCandidate(ref, candidates)
+	if err != nil || sure {
+		return match.feed, err
+	}
+
+	feed := fmt.Sprintf("%s [%s] %s", match.names[0], shortID(match.feed.ID), match.feed.Url)
+	if !isInteractive() {
+		return database.Feed{}, fmt.Errorf("%s only partly matches %s, use its name, URL or ID", ref, feed)
+	}
+	if err := confirm(fmt.Sprintf("%s matches %s, use it?", ref, feed), false); err != nil {
+		return database.Feed{}, err
+	}
+	return match.feed, nil
+}
+
+// resolveCandidate finds the feed referenced by ref and reports whether it
+// is sure: matched exactly, or picked by the user among several matches.
+func (s *state) resolveCandidate(ref string, candidates []feedCandidate) (feedCandidate, bool, error) {
+	matches, exact := matchFeeds(ref, candidates, s.trackingParams())
+	switch len(matches) {
+	case 0:
+		return feedCandidate{}, false, &feedNotFoundError{
+			ref:         ref,
+			suggestions: suggestFeeds(ref, candidates, maxFeedSuggestions),
+		}
+	case 1:
+		return matches[0], exact, nil
+	}
+
+	options := make([]string, len(matches))
+	for i, match := range matches {
+		options[i] = fmt.Sprintf("%s [%s] %s", match.names[0], shortID(match.feed.ID), match.feed.Url)
+	}
+
+	choice, err := choose(fmt.Sprintf("%s matches several feeds:", ref), options)
+	if errors.Is(err, errNoTerminal) {
+		return feedCandidate{}, false, fmt.Errorf("%s matches several feeds, use a URL or an ID:\n  %s", ref, strings.Join(options, "\n  "))
+	}
+	if err != nil {
+		return feedCandidate{}, false, err
+	}
+	return matches[choice], true, nil
+}
+
+// matchFeeds returns the feeds matching a reference, trying from the most to
+// the least precise: the exact name, the URL, a short or full ID, a part of
+// the name or URL and finally the letters of the name in order, all ignoring
+// case. It reports whether the matches are exact, that is not found by a
+// part of the name or URL.
+func matchFeeds(ref string, candidates []feedCandidate, trackingParams []string) ([]feedCandidate, bool) {
+	ref = strings.TrimSpace(ref)
+	lower := strings.ToLower(ref)
+
+	if normalized, err := normalizeURL(ref, trackingParams); err == nil {
+		return filterFeeds(candidates, func(c feedCandidate) bool {
+			feedURL, err := normalizeURL(c.feed.Url, trackingParams)
+			if err != nil {
+				feedURL = c.feed.Url
+			}
+			return feedURL == normalized || feedURL == otherScheme(normalized) || c.feed.Url == ref
+		}), true
+	}
+
+	// The tiers from exactTiers on only match a part of the reference.
+	const exactTiers = 2
+
+	tiers := []func(feedCandidate) bool{
+		func(c feedCandidate) bool {
+			return slices.ContainsFunc(c.names, func(name string) bool { return strings.EqualFold(name, ref) })
+		},
+		func(c feedCandidate) bool {
+			return isShortID(ref) && strings.HasPrefix(c.feed.ID.String(), lower)
+		},
+		func(c feedCandidate) bool {
+			return strings.Contains(strings.ToLower(c.feed.Url), lower) || slices.ContainsFunc(c.names, func(name string) bool {
+				return strings.Contains(strings.ToLower(name), lower)
+			})
+		},
+		func(c feedCandidate) bool {
+			return utf8.RuneCountInString(ref) >= 3 && slices.ContainsFunc(c.names, func(name string) bool {
+				return isSubsequence(lower, strings.ToLower(name))
+			})
+		},
+	}
+
+	for i, tier := range tiers {
+		if matches := filterFeeds(candidates, tier); len(matches) > 0 {
+			return matches, i < exactTiers
+		}
+	}
+	return nil, false
+}
+
+func filterFeeds(candidates []feedCandidate, keep func(feedCandidate) bool) []feedCandidate {
+	matches := []feedCandidate{}
+	for _, c := range candidates {
+		if keep(c) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// suggestFeeds returns the names of the feeds closest to a reference that
+// matched nothing, the closest first.
+func suggestFeeds(ref string, candidates []feedCandidate, limit int) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	lower := strings.ToLower(strings.TrimSpace(ref))
+	maxDistance := max(2, utf8.RuneCountInString(lower)/2)
+
+	suggestions := []suggestion{}
+	for _, c := range candidates {
+		best := -1
+		for _, name := range c.names {
+			if d := levenshtein(lower, strings.ToLower(name)); best < 0 || d < best {
+				best = d
+			}
+		}
+		if best >= 0 && best <= maxDistance {
+			suggestions = append(suggestions, suggestion{name: c.names[0], distance: best})
+		}
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b suggestion) int { return a.distance - b.distance })
+
+	names := []string{}
+	for _, s := range suggestions[:min(limit, len(suggestions))] {
+		names = append(names, fmt.Sprintf("%q", s.name))
+	}
+	return names
+}
+
+// isSubsequence reports whether the letters of sub appear in s in order.
+func isSubsequence(sub, s string) bool {
+	for _, r := range s {
+		if sub == "" {
+			return true
+		}
+		first, size := utf8.DecodeRuneInString(sub)
+		if r == first {
+			sub = sub[size:]
+		}
+	}
+	return sub == ""
+}
+
+// levenshtein is the number of letters to insert, delete or replace to turn
+// a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+func testFeedCandidates() []feedCandidate {
+	feed := func(id, name, url string) feedCandidate {
+		return feedCandidate{
+			feed:  database.Feed{ID: uuid.MustParse(id), Name: name, Url: url},
+			names: []string{name},
+		}
+	}
+	return []feedCandidate{
+		feed("1a2b3c4d-0000-4000-8000-000000000001", "The Go Blog", "https://go.dev/blog/feed.atom"),
+		feed("1a2bffff-0000-4000-8000-000000000002", "Hacker News", "https://news.ycombinator.com/rss"),
+		feed("5e6f7a8b-0000-4000-8000-000000000003", "Go Weekly", "https://golangweekly.com/rss/"),
+		feed("9c0d1e2f-0000-4000-8000-000000000004", "Lobsters", "https://lobste.rs/rss"),
+	}
+}
+
+func feedNames(candidates []feedCandidate) []string {
+	names := []string{}
+	for _, c := range candidates {
+		names = append(names, c.names[0])
+	}
+	return names
+}
+
+func TestMatchFeeds(t *testing.T) {
+	cases := []struct {
+		name  string
+		ref   string
+		want  []string
+		exact bool
+	}{
+		{name: "Exact name", ref: "the go blog", want: []string{"The Go Blog"}, exact: true},
+		{name: "URL", ref: "https://go.dev/blog/feed.atom", want: []string{"The Go Blog"}, exact: true},
+		{name: "URL variant", ref: "HTTP://lobste.rs/rss/?utm_source=x", want: []string{"Lobsters"}, exact: true},
+		{name: "Unknown URL", ref: "https://example.com/rss", want: []string{}, exact: true},
+		{name: "Short ID", ref: "5e6f7a8b", want: []string{"Go Weekly"}, exact: true},
+		{name: "Ambiguous ID prefix", ref: "1a2b", want: []string{"The Go Blog", "Hacker News"}, exact: true},
+		{name: "Part of the name", ref: "go", want: []string{"The Go Blog", "Go Weekly"}},
+		{name: "Part of the URL", ref: "ycombinator", want: []string{"Hacker News"}},
+		{name: "Letters in order", ref: "hnews", want: []string{"Hacker News"}},
+		{name: "Nothing", ref: "xyz", want: []string{}},
+	}
+
+	for _, c := range cases {
+		matches, exact := matchFeeds(c.ref, testFeedCandidates(), defaultTrackingParams)
+		if got := feedNames(matches); !slices.Equal(got, c.want) || exact != c.exact {
+			t.Errorf("%s: matchFeeds(%q) = %v, %t, want %v, %t", c.name, c.ref, got, exact, c.want, c.exact)
+		}
+	}
+}
+
+func TestSuggestFeeds(t *testing.T) {
+	cases := []struct {
+		ref  string
+		want []string
+	}{
+		{ref: "Lobstres", want: []string{`"Lobsters"`}},
+		{ref: "go weakly", want: []string{`"Go Weekly"`}},
+		{ref: "something else", want: []string{}},
+	}
+
+	for _, c := range cases {
+		got := suggestFeeds(c.ref, testFeedCandidates(), maxFeedSuggestions)
+		if !slices.Equal(got, c.want) {
+			t.Errorf("suggestFeeds(%q) = %v, want %v", c.ref, got, c.want)
+		}
+	}
+}
+
+func TestFeedNotFoundError(t *testing.T) {
+	err := &feedNotFoundError{ref: "lobstres", suggestions: []string{`"Lobsters"`}}
+	if !strings.Contains(err.Error(), `did you mean "Lobsters"?`) {
+		t.Errorf("Error() = %q, want a suggestion", err.Error())
+	}
+
+	var notFound *feedNotFoundError
+	if !errors.As(error(err), &notFound) {
+		t.Errorf("errors.As() failed on a feedNotFoundError")
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "abc", b: "", want: 3},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "lobsters", b: "lobstres", want: 2},
+		{a: "café", b: "cafe", want: 1},
+	}
+
+	for _, c := range cases {
+		if got := levenshtein(c.a, c.b); got != c.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+// uniqueViolation is the PostgreSQL error code of a duplicate key.
+const uniqueViolation = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// fetchFeedChecked fetches a feed that is about to be added to check that
+// it can be reached and is an RSS feed.
+func fetchFeedChecked(feedURL string) (*RSSFeed, error) {
+	rssFeed, err := fetchFeed(context.Background(), feedURL)
+
+	var fetchErr *feedError
+	switch {
+	case errors.As(err, &fetchErr) && fetchErr.kind == errFeedUnreachable:
+		return nil, fmt.Errorf("the feed %s is unreachable: %v", feedURL, fetchErr.cause)
+	case errors.As(err, &fetchErr) && fetchErr.kind == errNotAFeed:
+		return nil, fmt.Errorf("%s is not an RSS feed: %v", feedURL, fetchErr.cause)
+	case err != nil:
+		return nil, fmt.Errorf("the feed %s could not be fetched: %v", feedURL, err)
+	}
+
+	if rssFeed.Channel.Title == "" && len(rssFeed.Channel.Item) == 0 {
+		return nil, fmt.Errorf("%s is not an RSS feed: it has no channel", feedURL)
+	}
+	return rssFeed, nil
+}
+
+// defaultFeedName names a new feed after the title of its channel, or after
+// its host when the channel has no title.
+func defaultFeedName(rssFeed *RSSFeed, feedURL string) string {
+	if title := strings.Join(strings.Fields(rssFeed.Channel.Title), " "); title != "" {
+		return title
+	}
+	if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return feedURL
+}
+
+// createFollowedFeed fetches a feed that is not in the database yet, creates
+// it and follows it in a single transaction, so that a failure leaves no
+// feed without follower. Without a name the feed is named after its channel.
+// The feed is fetched before the transaction starts to keep it short.
+func (s *state) createFollowedFeed(user database.User, name, feedURL string) ([]database.CreateFeedFollowRow, error) {
+	rssFeed, err := fetchFeedChecked(feedURL)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = defaultFeedName(rssFeed, feedURL)
+	}
+
+	var feedFollows []database.CreateFeedFollowRow
+	err = s.withTx(func(tx *state) error {
+		feed, err := tx.db.CreateFeed(context.Background(),
+			database.CreateFeedParams{
+				ID:     uuid.New(),
+				Name:   name,
+				Url:    feedURL,
+				UserID: user.ID,
+			},
+		)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("the feed %s is already in the database, follow it instead", feedURL)
+		}
+		if err != nil {
+			return fmt.Errorf("the feed could not be created: %v", err)
+		}
+
+		feedFollows, err = tx.db.CreateFeedFollow(context.Background(),
+			database.CreateFeedFollowParams{
+				ID:     uuid.New(),
+				FeedID: feed.ID,
+				UserID: user.ID,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("the feed could not be followed: %v", err)
+		}
+		return nil
+	})
+	return feedFollows, err
+}
+
+// getOwnedFeed loads a feed that only its owner, the user who added it or
+// received it, may change.
+func (s *state) getOwnedFeed(user database.User, feedURL string) (database.Feed, error) {
+	feed, err := s.findFeedByURL(feedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("the feed %s was not found", feedURL)
+	}
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("the feed could not be loaded: %v", err)
+	}
+
+	if feed.UserID != user.ID {
+		return database.Feed{}, fmt.Errorf("the feed %s belongs to another user", feedURL)
+	}
+	return feed, nil
+}
+
+func handlerRemoveFeed(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	force := fs.Bool("force", false, "remove the feed even if other users follow it")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New("the command removefeed expect one argument: <feed-url> [--force]")
+	}
+
+	feed, err := s.getOwnedFeed(user, args[0])
+	if err != nil {
+		return err
+	}
+
+	followers, err := s.db.CountOtherFollowers(context.Background(),
+		database.CountOtherFollowersParams{
+			FeedID: feed.ID,
+			UserID: user.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the followers of the feed could not be counted: %v", err)
+	}
+
+	if followers > 0 && !*force {
+		return fmt.Errorf("%d other users follow %s: transfer it with transferfeed or remove it with --force", followers, feed.Url)
+	}
+
+	if _, err := s.db.DeleteFeed(context.Background(), feed.ID); err != nil {
+		return fmt.Errorf("the feed could not be removed: %v", err)
+	}
+
+	fmt.Printf("Feed removed: %s (%s)\n", feed.Name, feed.Url)
+	if followers > 0 {
+		fmt.Printf("%d other users were unfollowed\n", followers)
+	}
+	return nil
+}
+
+func handlerEditFeed(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	name := fs.String("name", "", "new name of the feed")
+	newURL := fs.String("url", "", "new URL of the feed")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 || (*name == "" && *newURL == "") {
+		return errors.New("the command editfeed expect a feed URL and --name or --url")
+	}
+
+	feed, err := s.getOwnedFeed(user, args[0])
+	if err != nil {
+		return err
+	}
+
+	params := database.UpdateFeedParams{
+		ID:   feed.ID,
+		Name: feed.Name,
+		Url:  feed.Url,
+	}
+	if *name != "" {
+		params.Name = *name
+	}
+	if *newURL != "" {
+		params.Url, err = s.normalizeURL(*newURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	feed, err = s.db.UpdateFeed(context.Background(), params)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("another feed already has the URL %s", params.Url)
+	}
+	if err != nil {
+		return fmt.Errorf("the feed could not be updated: %v", err)
+	}
+
+	fmt.Printf("Feed updated: %s (%s)\n", feed.Name, feed.Url)
+	return nil
+}
+
+func handlerTransferFeed(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 3 {
+		return errors.New("the command transferfeed expect two arguments: <feed-url> <user>")
+	}
+
+	feed, err := s.getOwnedFeed(user, cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.db.GetUserByName(context.Background(), cmd.args[2])
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("the user %s was not found", cmd.args[2])
+	}
+	if err != nil {
+		return fmt.Errorf("the user could not be loaded: %v", err)
+	}
+
+	err = s.db.SetFeedOwner(context.Background(),
+		database.SetFeedOwnerParams{
+			ID:     feed.ID,
+			UserID: owner.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the feed could not be transferred: %v", err)
+	}
+
+	fmt.Printf("The feed %s now belongs to %s\n", feed.Url, owner.Name)
+	return nil
+}
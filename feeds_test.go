@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "Duplicate key", err: &pq.Error{Code: "23505"}, want: true},
+		{name: "Wrapped", err: fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}), want: true},
+		{name: "Other database error", err: &pq.Error{Code: "23503"}, want: false},
+		{name: "Other error", err: errors.New("connection refused"), want: false},
+		{name: "No error", err: nil, want: false},
+	}
+
+	for _, c := range cases {
+		if got := isUniqueViolation(c.err); got != c.want {
+			t.Errorf("%s: isUniqueViolation() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDefaultFeedName(t *testing.T) {
+	withTitle := &RSSFeed{}
+	withTitle.Channel.Title = "  The Go\n  Blog "
+
+	cases := []struct {
+		name    string
+		feed    *RSSFeed
+		feedURL string
+		want    string
+	}{
+		{name: "Channel title", feed: withTitle, feedURL: "https://go.dev/blog/feed.atom", want: "The Go Blog"},
+		{name: "No title", feed: &RSSFeed{}, feedURL: "https://lobste.rs/rss", want: "lobste.rs"},
+		{name: "No host", feed: &RSSFeed{}, feedURL: "feed.xml", want: "feed.xml"},
+	}
+
+	for _, c := range cases {
+		if got := defaultFeedName(c.feed, c.feedURL); got != c.want {
+			t.Errorf("%s: defaultFeedName() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestFetchFeedChecked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss":
+			fmt.Fprint(w, `<rss><channel><title>Local feed</title></channel></rss>`)
+		case "/page":
+			fmt.Fprint(w, `<html><body><p>Not a feed<br></p></body></html>`)
+		case "/atom":
+			fmt.Fprint(w, `<feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title></feed>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	cases := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{name: "RSS feed", url: server.URL + "/rss"},
+		{name: "Not found", url: server.URL + "/missing", wantErr: "is unreachable: the server answered 404"},
+		{name: "Connection refused", url: closed.URL + "/rss", wantErr: "is unreachable"},
+		{name: "HTML page", url: server.URL + "/page", wantErr: "is not an RSS feed"},
+		{name: "No channel", url: server.URL + "/atom", wantErr: "is not an RSS feed: it has no channel"},
+	}
+
+	for _, c := range cases {
+		feed, err := fetchFeedChecked(c.url)
+		if c.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: fetchFeedChecked() error = %v", c.name, err)
+			} else if feed.Channel.Title != "Local feed" {
+				t.Errorf("%s: title = %q, want %q", c.name, feed.Channel.Title, "Local feed")
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%s: fetchFeedChecked() error = %v, want %q", c.name, err, c.wantErr)
+		}
+	}
+}
+
+func TestAggregateContinuesAfterErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ticks := make(chan time.Time, 2)
+	ticks <- time.Now()
+	ticks <- time.Now()
+	close(ticks)
+
+	scrapes := 0
+	aggregate(ticks, func() error {
+		scrapes++
+		_, err := fetchFeed(context.Background(), server.URL)
+		if err == nil {
+			t.Errorf("fetchFeed() of a failing server returned no error")
+		}
+		return err
+	})
+
+	if scrapes != 3 {
+		t.Errorf("aggregate() scraped %d times, want 3", scrapes)
+	}
+}
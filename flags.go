@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseFlags parses the flags of a command. Unlike flag.FlagSet.Parse it
+// accepts flags placed after positional arguments, so both
+// "gator markread --all" and "gator search go --feed <url>" work. It returns
+// the positional arguments in the order they were given.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseAge parses a duration like time.ParseDuration does, adding the "d"
+// (days) and "w" (weeks) units that are more natural for posts.
+func parseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if number, found := strings.CutSuffix(value, suffix); found {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+	return age, nil
+}
+
+// parseTimeArg parses a point in time given either as a date (2006-01-02),
+// an RFC 3339 timestamp or an age relative to now (7d, 12h).
+func parseTimeArg(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	age, err := parseAge(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or an age like 7d", value)
+	}
+	return time.Now().Add(-age), nil
+}
+
+// parseUntilArg parses the end of a time range like parseTimeArg. A date
+// includes the whole day, so its end is the next midnight.
+func parseUntilArg(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	return parseTimeArg(value)
+}
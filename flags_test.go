@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFlags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		wantArgs []string
+		wantFeed string
+		wantAll  bool
+	}{
+		{
+			name:     "Flags first",
+			args:     []string{"--all", "--feed", "https://a.dev/rss", "x"},
+			wantArgs: []string{"x"},
+			wantFeed: "https://a.dev/rss",
+			wantAll:  true,
+		},
+		{
+			name:     "Flags after positional",
+			args:     []string{"x", "y", "--feed", "https://a.dev/rss"},
+			wantArgs: []string{"x", "y"},
+			wantFeed: "https://a.dev/rss",
+		},
+		{
+			name:     "No flags",
+			args:     []string{},
+			wantArgs: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			feed := fs.String("feed", "", "")
+			all := fs.Bool("all", false, "")
+
+			args, err := parseFlags(fs, tc.args)
+			if err != nil {
+				t.Fatalf("parseFlags() error = %v", err)
+			}
+			if !reflect.DeepEqual(args, tc.wantArgs) {
+				t.Errorf("parseFlags() args = %v, want %v", args, tc.wantArgs)
+			}
+			if *feed != tc.wantFeed || *all != tc.wantAll {
+				t.Errorf("parseFlags() feed = %q all = %v, want %q %v", *feed, *all, tc.wantFeed, tc.wantAll)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	testCases := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "7d", want: 7 * 24 * time.Hour},
+		{value: "2w", want: 14 * 24 * time.Hour},
+		{value: "36h", want: 36 * time.Hour},
+		{value: "d", wantErr: true},
+		{value: "-3d", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseAge(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseAge(%q) error = %v, wantErr %v", tc.value, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeArg(t *testing.T) {
+	date, err := parseTimeArg("2024-03-01")
+	if err != nil {
+		t.Fatalf("parseTimeArg() error = %v", err)
+	}
+	if date.Year() != 2024 || date.Month() != time.March || date.Day() != 1 {
+		t.Errorf("parseTimeArg() = %v, want 2024-03-01", date)
+	}
+
+	relative, err := parseTimeArg("2d")
+	if err != nil {
+		t.Fatalf("parseTimeArg() error = %v", err)
+	}
+	if age := time.Since(relative); age < 47*time.Hour || age > 49*time.Hour {
+		t.Errorf("parseTimeArg(2d) is %v ago, want 48h", age)
+	}
+
+	if _, err := parseTimeArg("last tuesday"); err == nil {
+		t.Errorf("parseTimeArg() expected an error for an invalid date")
+	}
+}
+
+func TestParseUntilArg(t *testing.T) {
+	date, err := parseUntilArg("2024-02-29")
+	if err != nil {
+		t.Fatalf("parseUntilArg() error = %v", err)
+	}
+	if want := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local); !date.Equal(want) {
+		t.Errorf("parseUntilArg() = %v, want %v", date, want)
+	}
+
+	exact, err := parseUntilArg("2024-03-01T10:00:00Z")
+	if err != nil {
+		t.Fatalf("parseUntilArg() error = %v", err)
+	}
+	if want := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC); !exact.Equal(want) {
+		t.Errorf("parseUntilArg() = %v, want %v", exact, want)
+	}
+}
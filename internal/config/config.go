@@ -13,6 +13,9 @@ const configFileName = ".gatorconfig.json"
 type Config struct {
 	DBURL           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	// TrackingParams replaces the query parameters stripped from feed and
+	// post URLs. A trailing * matches every parameter with that prefix.
+	TrackingParams []string `json:"tracking_params,omitempty"`
 }
 
 func Read() (Config, error) {
@@ -6,11 +6,18 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Cluster struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type Feed struct {
 	ID            uuid.UUID
 	CreatedAt     time.Time
@@ -19,25 +26,89 @@ type Feed struct {
 	Url           string
 	UserID        uuid.UUID
 	LastFetchedAt sql.NullTime
+	PausedAt      sql.NullTime
 }
 
 type FeedFollow struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	UserID           uuid.UUID
+	FeedID           uuid.UUID
+	Title            sql.NullString
+	PausedAt         sql.NullTime
+	PausedUntil      sql.NullTime
+	FetchFullContent bool
+}
+
+type FeedFollowTag struct {
+	FeedFollowID uuid.UUID
+	TagID        uuid.UUID
+	CreatedAt    time.Time
+}
+
+type Post struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Title          string
+	Url            string
+	Description    string
+	PublishedAt    sql.NullTime
+	FeedID         uuid.NullUUID
+	SearchVector   interface{}
+	Author         string
+	Categories     string
+	Simhash        sql.NullInt64
+	ClusterID      uuid.NullUUID
+	RawDescription sql.NullString
+	FullContent    sql.NullString
+}
+
+type PostLink struct {
+	Url    string
+	PostID uuid.UUID
+}
+
+type PostState struct {
+	UserID        uuid.UUID
+	PostID        uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ReadAt        sql.NullTime
+	StarredAt     sql.NullTime
+	MutedAt       sql.NullTime
+	HighlightedAt sql.NullTime
+	DismissedAt   sql.NullTime
+	Relevance     sql.NullFloat64
+	BrowseIndex   sql.NullInt32
+	OpenedAt      sql.NullTime
+}
+
+type RelevanceModel struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Model     json.RawMessage
+}
+
+type Rule struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	UserID    uuid.UUID
-	FeedID    uuid.UUID
+	Field     string
+	MatchType string
+	Pattern   string
+	Action    string
 }
 
-type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description string
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+type Tag struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
 }
 
 type User struct {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createFeed = `-- name: CreateFeed :one
@@ -22,7 +23,7 @@ VALUES (
     $2,
     $3,
     $4
-) RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+) RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, paused_at
 `
 
 type CreateFeedParams struct {
@@ -48,6 +49,7 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.PausedAt,
 	)
 	return i, err
 }
@@ -61,9 +63,9 @@ WITH inserted_follow AS (
         NOW(),
         $2,
         $3
-    ) RETURNING id, created_at, updated_at, user_id, feed_id
+    ) RETURNING id, created_at, updated_at, user_id, feed_id, title, paused_at, paused_until, fetch_full_content
 ) SELECT 
-inserted_follow.id, inserted_follow.created_at, inserted_follow.updated_at, inserted_follow.user_id, inserted_follow.feed_id,
+inserted_follow.id, inserted_follow.created_at, inserted_follow.updated_at, inserted_follow.user_id, inserted_follow.feed_id, inserted_follow.title, inserted_follow.paused_at, inserted_follow.paused_until, inserted_follow.fetch_full_content,
 feeds.name as feed_name,
 feeds.url as feed_url,
 users.name as user_name
@@ -79,14 +81,18 @@ type CreateFeedFollowParams struct {
 }
 
 type CreateFeedFollowRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	FeedUrl   string
-	UserName  string
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	UserID           uuid.UUID
+	FeedID           uuid.UUID
+	Title            sql.NullString
+	PausedAt         sql.NullTime
+	PausedUntil      sql.NullTime
+	FetchFullContent bool
+	FeedName         string
+	FeedUrl          string
+	UserName         string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) ([]CreateFeedFollowRow, error) {
@@ -104,6 +110,10 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.Title,
+			&i.PausedAt,
+			&i.PausedUntil,
+			&i.FetchFullContent,
 			&i.FeedName,
 			&i.FeedUrl,
 			&i.UserName,
@@ -122,7 +132,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 }
 
 const createPost = `-- name: CreatePost :exec
-INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, simhash, raw_description)
 VALUES (
     $1,
     NOW(),
@@ -131,17 +141,25 @@ VALUES (
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
 )
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	Title       string
-	Url         string
-	Description string
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID             uuid.UUID
+	Title          string
+	Url            string
+	Description    string
+	PublishedAt    sql.NullTime
+	FeedID         uuid.NullUUID
+	Author         string
+	Categories     string
+	Simhash        sql.NullInt64
+	RawDescription sql.NullString
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
@@ -152,6 +170,10 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Author,
+		arg.Categories,
+		arg.Simhash,
+		arg.RawDescription,
 	)
 	return err
 }
@@ -207,7 +229,7 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, paused_at FROM feeds WHERE url = $1
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -221,6 +243,35 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.PausedAt,
+	)
+	return i, err
+}
+
+const getFeedFollowByURL = `-- name: GetFeedFollowByURL :one
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.title, feed_follows.paused_at, feed_follows.paused_until, feed_follows.fetch_full_content FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1 AND feeds.url = $2
+`
+
+type GetFeedFollowByURLParams struct {
+	UserID uuid.UUID
+	Url    string
+}
+
+func (q *Queries) GetFeedFollowByURL(ctx context.Context, arg GetFeedFollowByURLParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollowByURL, arg.UserID, arg.Url)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Title,
+		&i.PausedAt,
+		&i.PausedUntil,
+		&i.FetchFullContent,
 	)
 	return i, err
 }
@@ -228,24 +279,46 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 const getFeedFollowForUser = `-- name: GetFeedFollowForUser :many
 SELECT 
     users.name AS user_name,
-    feeds.name AS feed_name,
+    COALESCE(feed_follows.title, feeds.name) AS feed_name,
     feeds.url AS feed_url,
-    feeds.last_fetched_at as last_fetched_at
+    feeds.last_fetched_at as last_fetched_at,
+    COALESCE(array_agg(tags.name ORDER BY tags.name) FILTER (WHERE tags.name IS NOT NULL), '{}')::text[] AS tags,
+    feed_follows.paused_at,
+    feed_follows.paused_until,
+    feeds.paused_at AS feed_paused_at
 FROM feed_follows
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
 INNER JOIN users ON users.id = feed_follows.user_id
+LEFT JOIN feed_follow_tags ON feed_follow_tags.feed_follow_id = feed_follows.id
+LEFT JOIN tags ON tags.id = feed_follow_tags.tag_id
 WHERE feed_follows.user_id = $1
+    AND ($2::text IS NULL OR EXISTS (
+        SELECT 1 FROM feed_follow_tags
+        INNER JOIN tags ON tags.id = feed_follow_tags.tag_id
+        WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = $2
+    ))
+GROUP BY feed_follows.id, users.name, feeds.name, feeds.url, feeds.last_fetched_at, feeds.paused_at
+ORDER BY feeds.name
 `
 
+type GetFeedFollowForUserParams struct {
+	UserID uuid.UUID
+	Tag    sql.NullString
+}
+
 type GetFeedFollowForUserRow struct {
 	UserName      string
 	FeedName      string
 	FeedUrl       string
 	LastFetchedAt sql.NullTime
+	Tags          []string
+	PausedAt      sql.NullTime
+	PausedUntil   sql.NullTime
+	FeedPausedAt  sql.NullTime
 }
 
-func (q *Queries) GetFeedFollowForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getFeedFollowForUser, userID)
+func (q *Queries) GetFeedFollowForUser(ctx context.Context, arg GetFeedFollowForUserParams) ([]GetFeedFollowForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowForUser, arg.UserID, arg.Tag)
 	if err != nil {
 		return nil, err
 	}
@@ -258,6 +331,10 @@ func (q *Queries) GetFeedFollowForUser(ctx context.Context, userID uuid.UUID) ([
 			&i.FeedName,
 			&i.FeedUrl,
 			&i.LastFetchedAt,
+			pq.Array(&i.Tags),
+			&i.PausedAt,
+			&i.PausedUntil,
+			&i.FeedPausedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -273,14 +350,16 @@ func (q *Queries) GetFeedFollowForUser(ctx context.Context, userID uuid.UUID) ([
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT feeds.name as rss_name, feeds.url as rss_url, users.name as user_name FROM feeds
+SELECT feeds.id, feeds.name as rss_name, feeds.url as rss_url, users.name as user_name, feeds.paused_at FROM feeds
 INNER JOIN users ON feeds.user_id = users.id
 `
 
 type GetFeedsRow struct {
+	ID       uuid.UUID
 	RssName  string
 	RssUrl   string
 	UserName string
+	PausedAt sql.NullTime
 }
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
@@ -292,7 +371,13 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	var items []GetFeedsRow
 	for rows.Next() {
 		var i GetFeedsRow
-		if err := rows.Scan(&i.RssName, &i.RssUrl, &i.UserName); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.RssName,
+			&i.RssUrl,
+			&i.UserName,
+			&i.PausedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -307,35 +392,113 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, url FROM feeds
+SELECT id, url, EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id AND feed_follows.fetch_full_content
+) AS fetch_full_content
+FROM feeds
+WHERE paused_at IS NULL
 ORDER BY last_fetched_at ASC NULLS FIRST
 `
 
 type GetNextFeedToFetchRow struct {
-	ID  uuid.UUID
-	Url string
+	ID               uuid.UUID
+	Url              string
+	FetchFullContent bool
 }
 
 func (q *Queries) GetNextFeedToFetch(ctx context.Context) (GetNextFeedToFetchRow, error) {
 	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
 	var i GetNextFeedToFetchRow
-	err := row.Scan(&i.ID, &i.Url)
+	err := row.Scan(&i.ID, &i.Url, &i.FetchFullContent)
 	return i, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT id, created_at, updated_at, title, url, description, published_at, feed_id FROM posts
+WITH visible AS (
+    SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.search_vector, posts.author, posts.categories, posts.simhash, posts.cluster_id, posts.raw_description, posts.full_content, COALESCE(feed_follows.title, feeds.name) AS feed_name, post_states.read_at, post_states.highlighted_at, post_states.relevance, post_states.starred_at,
+        ROW_NUMBER() OVER (
+            PARTITION BY COALESCE(posts.cluster_id, posts.id)
+            ORDER BY posts.published_at ASC NULLS LAST, posts.id
+        ) AS cluster_rank
+    FROM posts
+    INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+    INNER JOIN feeds ON feeds.id = posts.feed_id
+    LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+    WHERE feed_follows.user_id = $1
+        AND (NOT $2::bool OR post_states.read_at IS NULL)
+        AND ($3::bool OR post_states.muted_at IS NULL)
+        AND (feed_follows.paused_at IS NULL OR feed_follows.paused_until <= NOW())
+        AND ($4::text IS NULL OR EXISTS (
+            SELECT 1 FROM feed_follow_tags
+            INNER JOIN tags ON tags.id = feed_follow_tags.tag_id
+            WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = $4
+        ))
+        AND ($5::text IS NULL OR feeds.url = $5)
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, search_vector, author, categories, simhash, cluster_id, raw_description, full_content, feed_name, read_at, highlighted_at, relevance, starred_at
+FROM visible
+WHERE NOT $6::bool OR cluster_rank = 1
+ORDER BY
+    CASE WHEN $7::bool THEN relevance END DESC NULLS LAST,
+    published_at DESC NULLS LAST
+LIMIT $8
 `
 
-func (q *Queries) GetPostsForUser(ctx context.Context) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser)
+type GetPostsForUserParams struct {
+	UserID           uuid.UUID
+	UnreadOnly       bool
+	IncludeMuted     bool
+	Tag              sql.NullString
+	FeedUrl          sql.NullString
+	CollapseClusters bool
+	ByRelevance      bool
+	Limit            int32
+}
+
+type GetPostsForUserRow struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Title          string
+	Url            string
+	Description    string
+	PublishedAt    sql.NullTime
+	FeedID         uuid.NullUUID
+	SearchVector   interface{}
+	Author         string
+	Categories     string
+	Simhash        sql.NullInt64
+	ClusterID      uuid.NullUUID
+	RawDescription sql.NullString
+	FullContent    sql.NullString
+	FeedName       string
+	ReadAt         sql.NullTime
+	HighlightedAt  sql.NullTime
+	Relevance      sql.NullFloat64
+	StarredAt      sql.NullTime
+}
+
+// Clusters are collapsed over the posts left after filtering, so a cluster
+// still shows up when its first post is read, muted or from another tag.
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.IncludeMuted,
+		arg.Tag,
+		arg.FeedUrl,
+		arg.CollapseClusters,
+		arg.ByRelevance,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForUserRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForUserRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -345,6 +508,18 @@ func (q *Queries) GetPostsForUser(ctx context.Context) ([]Post, error) {
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.SearchVector,
+			&i.Author,
+			&i.Categories,
+			&i.Simhash,
+			&i.ClusterID,
+			&i.RawDescription,
+			&i.FullContent,
+			&i.FeedName,
+			&i.ReadAt,
+			&i.HighlightedAt,
+			&i.Relevance,
+			&i.StarredAt,
 		); err != nil {
 			return nil, err
 		}
@@ -426,3 +601,26 @@ func (q *Queries) Reset(ctx context.Context) error {
 	_, err := q.db.ExecContext(ctx, reset)
 	return err
 }
+
+const setFeedFollowTitle = `-- name: SetFeedFollowTitle :execrows
+UPDATE feed_follows
+SET title = $1, updated_at = NOW()
+FROM feeds
+WHERE feeds.id = feed_follows.feed_id
+    AND feed_follows.user_id = $2
+    AND feeds.url = $3
+`
+
+type SetFeedFollowTitleParams struct {
+	Title  sql.NullString
+	UserID uuid.UUID
+	Url    string
+}
+
+func (q *Queries) SetFeedFollowTitle(ctx context.Context, arg SetFeedFollowTitleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFollowTitle, arg.Title, arg.UserID, arg.Url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
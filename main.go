@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"html"
 
 	//"strconv"
@@ -19,11 +20,14 @@ import (
 
 	"errors"
 	"os"
+	"strings"
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db     *database.Queries
+	conn   *sql.DB
+	cfg    *config.Config
+	output outputOptions
 }
 
 type command struct {
@@ -89,10 +93,11 @@ func handlerRegister(s *state, cmd command) error {
 		},
 	)
 
+	if isUniqueViolation(err) {
+		return fmt.Errorf("the user %s already exists", cmd.args[1])
+	}
 	if err != nil {
-		fmt.Printf("no se pudo registrar el usuario %s: %v\n", cmd.args[1], err)
-		os.Exit(1)
-		return err
+		return fmt.Errorf("the user %s could not be registered: %v", cmd.args[1], err)
 	}
 
 	if err := s.cfg.SetUser(cmd.args[1]); err != nil {
@@ -103,20 +108,6 @@ func handlerRegister(s *state, cmd command) error {
 	return nil
 }
 
-func handlerReset(s *state, cmd command) error {
-	if len(cmd.args) != 1 {
-		return errors.New("the command login expect no argument")
-	}
-
-	if err := s.db.Reset(context.Background()); err != nil {
-		return err
-	}
-
-	fmt.Print("all user has been erased from the database\n")
-
-	return nil
-}
-
 func handlerUsers(s *state, cmd command) error {
 	if len(cmd.args) != 1 {
 		return errors.New("the command login expect no argument")
@@ -128,6 +119,14 @@ func handlerUsers(s *state, cmd command) error {
 		return err
 	}
 
+	if s.output.format != outputText {
+		rows := make([]UserRow, 0, len(users))
+		for _, user := range users {
+			rows = append(rows, UserRow{Name: user.Name, Current: user.Name == s.cfg.CurrentUserName})
+		}
+		return printRows(os.Stdout, s.output, rows)
+	}
+
 	for _, user := range users {
 		if user.Name == s.cfg.CurrentUserName {
 			fmt.Printf("* %s (current)\n", user.Name)
@@ -140,35 +139,30 @@ func handlerUsers(s *state, cmd command) error {
 }
 
 func handlerAddFeed(s *state, cmd command, user database.User) error {
-	if len(cmd.args) != 3 {
-		return errors.New("the command addFeed expect two arguments")
+	if len(cmd.args) != 2 && len(cmd.args) != 3 {
+		return errors.New("the command addFeed expect one or two arguments: [name] <url>")
 	}
 
-	feed, err := s.db.CreateFeed(
-		context.Background(),
-		database.CreateFeedParams{
-			ID:     uuid.New(),
-			Name:   cmd.args[1],
-			Url:    cmd.args[2],
-			UserID: user.ID,
-		},
-	)
+	name, feedURL := "", cmd.args[1]
+	if len(cmd.args) == 3 {
+		name, feedURL = cmd.args[1], cmd.args[2]
+	}
 
+	feedURL, err := s.normalizeURL(feedURL)
 	if err != nil {
-		return fmt.Errorf("The RSS feed could not be save in the database: %v", err)
+		return err
 	}
 
-	_, err = s.db.CreateFeedFollow(
-		context.Background(),
-		database.CreateFeedFollowParams{
-			ID:     uuid.New(),
-			FeedID: feed.ID,
-			UserID: feed.UserID,
-		},
-	)
+	existing, err := s.findFeedByURL(feedURL)
+	if err == nil {
+		return fmt.Errorf("the feed %s is already in the database as %s, follow it instead", existing.Url, existing.Name)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("the feed could not be looked up: %v", err)
+	}
 
-	if err != nil {
-		return fmt.Errorf("The feedfollow was not created: %v", err)
+	if _, err := s.createFollowedFeed(user, name, feedURL); err != nil {
+		return err
 	}
 
 	fmt.Println("The RSS feedwas saved successfully!")
@@ -186,12 +180,30 @@ func handlerFeeds(s *state, cmd command) error {
 		return err
 	}
 
+	if s.output.format != outputText {
+		rows := make([]FeedRow, 0, len(feeds))
+		for _, feed := range feeds {
+			rows = append(rows, FeedRow{
+				ID:        shortID(feed.ID),
+				Name:      feed.RssName,
+				URL:       feed.RssUrl,
+				CreatedBy: feed.UserName,
+				Paused:    feed.PausedAt.Valid,
+			})
+		}
+		return printRows(os.Stdout, s.output, rows)
+	}
+
 	fmt.Printf("******List of all feeds in the database**********\n\n")
 
 	for _, feed := range feeds {
-		fmt.Printf("Name of the RSS feed: %s\n", feed.RssName)
+		fmt.Printf("Name of the RSS feed: %s\n", stripControl(feed.RssName))
+		fmt.Printf("                  ID: %s\n", shortID(feed.ID))
 		fmt.Printf("                 URL: %s\n", feed.RssUrl)
 		fmt.Printf("  User who create it: %s\n", feed.UserName)
+		if feed.PausedAt.Valid {
+			fmt.Printf("              Paused: not fetched by agg\n")
+		}
 		fmt.Printf("-----------------\n\n")
 	}
 
@@ -210,28 +222,52 @@ func handlerAgg(s *state, cmd command) error {
 	}
 
 	ticker := time.NewTicker(timeBetweenRequests)
+	aggregate(ticker.C, s.scrapeFeeds)
+	return nil
+}
 
-	for ; ; <-ticker.C {
+// aggregate scrapes a feed now and on every tick until ticks is closed. An
+// error is logged and does not stop the aggregator: the failing feed was
+// marked fetched, so the next tick moves on to another feed.
+func aggregate(ticks <-chan time.Time, scrape func() error) {
+	for {
 		fmt.Printf("Scraping the web: %s\n", time.Now().GoString())
-		err := s.scrapeFeeds()
+		if err := scrape(); err != nil {
+			fmt.Printf("The feed could not be scraped: %v\n", err)
+		}
 
-		if err != nil {
-			return err
+		if _, ok := <-ticks; !ok {
+			return
 		}
 	}
-
-	return nil
 }
 
 func handlerFollow(s *state, cmd command, user database.User) error {
 	if len(cmd.args) != 2 {
-		return errors.New("the command follow expect one argument")
+		return errors.New("the command follow expect one argument: <feed name, ID or URL>")
 	}
 
-	rssFeed, err := s.db.GetFeedByURL(context.Background(), cmd.args[1])
+	candidates, err := s.allFeedCandidates()
+	if err != nil {
+		return err
+	}
+
+	rssFeed, err := s.resolveFeed(cmd.args[1], candidates)
 
+	// An unknown URL is fetched and followed as a new feed.
+	var notFound *feedNotFoundError
+	if errors.As(err, &notFound) {
+		if feedURL, urlErr := s.normalizeURL(cmd.args[1]); urlErr == nil {
+			feedFollows, err := s.createFollowedFeed(user, "", feedURL)
+			if err != nil {
+				return err
+			}
+			printFeedFollows(feedFollows)
+			return nil
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("The URL was not found in the database: %v", err)
+		return err
 	}
 
 	feedFollows, err := s.db.CreateFeedFollow(
@@ -243,10 +279,18 @@ func handlerFollow(s *state, cmd command, user database.User) error {
 		},
 	)
 
+	if isUniqueViolation(err) {
+		return fmt.Errorf("you already follow %s", rssFeed.Name)
+	}
 	if err != nil {
 		return fmt.Errorf("The feed follow could not be created: %v", err)
 	}
 
+	printFeedFollows(feedFollows)
+	return nil
+}
+
+func printFeedFollows(feedFollows []database.CreateFeedFollowRow) {
 	fmt.Printf("--list of all feed follows---\n\n")
 
 	for _, feedFollow := range feedFollows {
@@ -255,27 +299,68 @@ func handlerFollow(s *state, cmd command, user database.User) error {
 		fmt.Printf("URL of the feed: %s\n", feedFollow.UserName)
 		fmt.Printf("-------------\n\n")
 	}
-	return nil
 }
 
 func handlerFollowing(s *state, cmd command, user database.User) error {
-	if len(cmd.args) != 1 {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	tag := fs.String("tag", "", "only list the feeds with this tag")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 0 {
 		return errors.New("the command following expect no argument")
 	}
 
-	feedFollows, err := s.db.GetFeedFollowForUser(context.Background(), user.ID)
+	feedFollows, err := s.db.GetFeedFollowForUser(context.Background(),
+		database.GetFeedFollowForUserParams{
+			UserID: user.ID,
+			Tag:    sql.NullString{String: *tag, Valid: *tag != ""},
+		},
+	)
 
 	if err != nil {
 		return fmt.Errorf("The user wasnt found in the database: %v", err)
 	}
 
+	now := time.Now()
+
+	if s.output.format != outputText {
+		rows := make([]FollowRow, 0, len(feedFollows))
+		for _, feedFollow := range feedFollows {
+			paused := isPaused(feedFollow.PausedAt, feedFollow.PausedUntil, now)
+			rows = append(rows, FollowRow{
+				Name:          feedFollow.FeedName,
+				URL:           feedFollow.FeedUrl,
+				User:          feedFollow.UserName,
+				LastFetchedAt: nullTime(feedFollow.LastFetchedAt.Valid, feedFollow.LastFetchedAt.Time),
+				Tags:          append([]string{}, feedFollow.Tags...),
+				Paused:        paused,
+				PausedUntil:   nullTime(paused && feedFollow.PausedUntil.Valid, feedFollow.PausedUntil.Time),
+				FeedPaused:    feedFollow.FeedPausedAt.Valid,
+			})
+		}
+		return printRows(os.Stdout, s.output, rows)
+	}
+
 	fmt.Printf("--list of all feed follows---\n\n")
 
 	for _, feedFollow := range feedFollows {
-		fmt.Printf("  name of the feed: %s\n", feedFollow.FeedName)
+		fmt.Printf("  name of the feed: %s\n", stripControl(feedFollow.FeedName))
 		fmt.Printf("   URL of the feed: %s\n", feedFollow.FeedUrl)
 		fmt.Printf("  user of the feed: %s\n", feedFollow.UserName)
 		fmt.Printf(" Last time fetched: %v\n", feedFollow.LastFetchedAt)
+		if len(feedFollow.Tags) > 0 {
+			fmt.Printf("              Tags: %s\n", strings.Join(feedFollow.Tags, ", "))
+		}
+		if pause := pauseState(feedFollow.PausedAt, feedFollow.PausedUntil, now); pause != "" {
+			fmt.Printf("            Paused: %s\n", pause)
+		}
+		if feedFollow.FeedPausedAt.Valid {
+			fmt.Printf("       Feed paused: not fetched by agg\n")
+		}
 		fmt.Printf("-------------\n\n")
 	}
 	return nil
@@ -283,55 +368,94 @@ func handlerFollowing(s *state, cmd command, user database.User) error {
 
 func handlerUnfollow(s *state, cmd command, user database.User) error {
 	if len(cmd.args) != 2 {
-		return errors.New("the command following expect one argument")
+		return errors.New("the command unfollow expect one argument: <feed name, ID or URL>")
 	}
 
-	err := s.db.DeleteFeedFollow(context.Background(),
+	candidates, err := s.followedFeedCandidates(user)
+	if err != nil {
+		return err
+	}
+
+	feed, err := s.resolveFeedToChange(cmd.args[1], candidates)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.DeleteFeedFollow(context.Background(),
 		database.DeleteFeedFollowParams{
-			Url:    cmd.args[1],
+			Url:    feed.Url,
 			UserID: user.ID,
 		},
 	)
 
 	if err != nil {
-		return fmt.Errorf("The URL is not in the database: %v", err)
+		return fmt.Errorf("the feed could not be unfollowed: %v", err)
 	}
 
+	fmt.Printf("You unfollowed %s\n", feed.Name)
 	return nil
 }
 
-func handlerBrowse(s *state, cmd command, user database.User) error {
-	if len(cmd.args) > 2 || len(cmd.args) < 1 {
-		return errors.New("the command following expect one or two argument")
+// handlerRenameFollow sets the name the user sees for a followed feed. Without
+// a name the feed goes back to its global name.
+func handlerRenameFollow(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 && len(cmd.args) != 3 {
+		return errors.New("the command rename-follow expect a feed name, ID or URL and an optional name")
 	}
 
-	posts, err := s.db.GetPostsForUser(context.Background())
+	title := sql.NullString{}
+	if len(cmd.args) == 3 && cmd.args[2] != "" {
+		title = sql.NullString{String: cmd.args[2], Valid: true}
+	}
 
+	candidates, err := s.followedFeedCandidates(user)
 	if err != nil {
-		fmt.Printf("Posts could no be loaded from the database\n", err)
 		return err
 	}
 
-	for _, post := range posts {
-		fmt.Printf("      Title: %s\n", post.Title)
-		fmt.Printf("Description: %s\n", post.Description)
-		fmt.Printf("        URL: %s\n", post.Url)
-		fmt.Printf("------------------: %s\n\n")
+	feed, err := s.resolveFeed(cmd.args[1], candidates)
+	if err != nil {
+		return err
 	}
 
+	count, err := s.db.SetFeedFollowTitle(context.Background(),
+		database.SetFeedFollowTitleParams{
+			Title:  title,
+			UserID: user.ID,
+			Url:    feed.Url,
+		},
+	)
+
 	if err != nil {
-		return fmt.Errorf("The URL is not in the database: %v", err)
+		return fmt.Errorf("the feed follow could not be renamed: %v", err)
 	}
 
+	if count == 0 {
+		return fmt.Errorf("you are not following %s", feed.Url)
+	}
+
+	if title.Valid {
+		fmt.Printf("%s is now shown as %s\n", feed.Url, title.String)
+	} else {
+		fmt.Printf("%s is shown with its feed name again\n", feed.Url)
+	}
 	return nil
 }
 
+func handlerBrowse(s *state, cmd command, user database.User) error {
+	opts, err := parseBrowseOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	return s.showPosts(user, opts)
+}
+
 func middleWareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
 	return func(s *state, cmd command) error {
 		user, err := s.db.GetUserByName(context.Background(), s.cfg.CurrentUserName)
 		if err != nil {
-			fmt.Printf("The user could not be authenticated.\n")
-			os.Exit(1)
+			return fmt.Errorf("the user could not be authenticated: %v", err)
 		}
 		return handler(s, cmd, user)
 	}
@@ -340,61 +464,128 @@ func middleWareLoggedIn(handler func(s *state, cmd command, user database.User)
 func (s *state) scrapeFeeds() error {
 	feed, err := s.db.GetNextFeedToFetch(context.Background())
 
+	// With no feed or every feed paused, agg waits for the next tick.
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Println("No RSS feed to fetch")
+		return nil
+	}
 	if err != nil {
-		fmt.Printf("No more RSS feed to fetch")
-		return err
+		return fmt.Errorf("the next RSS feed could not be loaded: %v", err)
 	}
 
 	if err := s.db.MarkFeedFetched(context.Background(), feed.ID); err != nil {
-		fmt.Printf("Could not marked the feed as fetch!: %s", err)
-		return err
+		return fmt.Errorf("the feed %s could not be marked as fetched: %v", feed.Url, err)
 	}
 
 	var rssFeed *RSSFeed
 
 	rssFeed, err = fetchFeed(context.Background(), feed.Url)
 	if err != nil {
-		fmt.Printf("The URL could not be fetch: %", err)
-		return err
+		return fmt.Errorf("the URL %s could not be fetched: %v", feed.Url, err)
 	}
 
-	fmt.Printf("RSS feed title: %s\n\n", html.UnescapeString(rssFeed.Channel.Title))
+	followers, err := s.loadFeedRules(feed.ID)
+	if err != nil {
+		return fmt.Errorf("the filter rules of %s could not be loaded: %v", feed.Url, err)
+	}
+
+	models, err := s.loadFeedModels(feed.ID)
+	if err != nil {
+		return fmt.Errorf("the relevance models of %s could not be loaded: %v", feed.Url, err)
+	}
+
+	fmt.Printf("RSS feed title: %s\n\n", stripControl(html.UnescapeString(rssFeed.Channel.Title)))
+	articles := []articleJob{}
 	for _, item := range rssFeed.Channel.Item {
-		pubTime, _ := time.Parse(time.RFC3339, item.PubDate)
-		_ = s.db.CreatePost(context.Background(),
-			database.CreatePostParams{
-				ID:          uuid.New(),
-				Title:       html.UnescapeString(item.Title),
-				Url:         item.Link,
-				Description: item.Description,
-				FeedID:      feed.ID,
-				PublishedAt: sql.NullTime{Time: pubTime, Valid: true},
-			},
-		)
+		// Posts stored before URLs were normalized keep their original URL,
+		// and posts moved to their canonical URL keep the link of the feed.
+		postURL := s.normalizeLink(item.Link)
+		if exists, err := s.db.PostExists(context.Background(), postURL); err != nil || exists {
+			continue
+		}
+		if postURL != item.Link {
+			if exists, err := s.db.PostExists(context.Background(), item.Link); err != nil || exists {
+				continue
+			}
+		}
+
+		pubTime, pubTimeOK := parsePubDate(item.PubDate)
+		post := database.CreatePostParams{
+			ID:          uuid.New(),
+			Title:       html.UnescapeString(item.Title),
+			Url:         postURL,
+			Description: sanitizeHTML(item.Description, item.Link),
+			FeedID:      uuid.NullUUID{UUID: feed.ID, Valid: true},
+			PublishedAt: sql.NullTime{Time: pubTime, Valid: pubTimeOK},
+			Author:      item.AuthorName(),
+			Categories:  strings.Join(item.Categories, categorySeparator),
+
+			RawDescription: sql.NullString{String: item.Description, Valid: true},
+		}
+		hash := simhash(post.Title, post.Description)
+		post.Simhash = sql.NullInt64{Int64: int64(hash), Valid: true}
+
+		// Posts already stored fail on the unique URL and were filtered
+		// when they were first ingested.
+		if err := s.db.CreatePost(context.Background(), post); err != nil {
+			if !isUniqueViolation(err) {
+				fmt.Printf("The post %s could not be stored: %v\n", post.Url, err)
+			}
+			continue
+		}
+
+		err := s.applyFeedRules(followers, post.ID, ruleTarget{
+			title:       post.Title,
+			description: post.Description,
+			author:      post.Author,
+			categories:  item.Categories,
+		})
+
+		if err != nil {
+			fmt.Printf("The filter rules could not be applied to %s: %v\n", post.Url, err)
+		}
+
+		if err := s.scorePost(models, post.ID, postTokens(post.Title, post.Description, feed.Url)); err != nil {
+			fmt.Printf("The relevance of %s could not be scored: %v\n", post.Url, err)
+		}
+
+		if err := s.clusterPost(post.ID, feed.ID, hash); err != nil {
+			fmt.Printf("The post %s could not be clustered: %v\n", post.Url, err)
+		}
+
+		if feed.FetchFullContent {
+			articles = append(articles, articleJob{postID: post.ID, link: post.Url})
+		}
 	}
 
+	s.storeFullContents(articles)
 	return nil
 
 }
 
 func main() {
 	gatorState := state{}
+	output, args, err := parseGlobalOptions(os.Args[1:])
+	if err != nil {
+		exitWithError(outputText, err.Error())
+	}
+	gatorState.output = output
+
 	cfg, err := config.Read()
 	if err != nil {
-		fmt.Printf("The configuration file could no be read: %v\n", err)
-		os.Exit(1)
+		exitWithError(output.format, fmt.Sprintf("The configuration file could no be read: %v", err))
 	}
 
 	db, err := sql.Open("postgres", cfg.DBURL)
 	if err != nil {
-		fmt.Printf("The database could not be opened: %v\n", err)
-		os.Exit(1)
+		exitWithError(output.format, fmt.Sprintf("The database could not be opened: %v", err))
 	}
 
 	dbQueries := database.New(db)
 
 	gatorState.cfg = &cfg
 	gatorState.db = dbQueries
+	gatorState.conn = db
 
 	gatorCommands := commands{
 		cmdNames:    []string{},
@@ -411,7 +602,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := gatorCommands.register("reset", handlerReset); err != nil {
+	if err := gatorCommands.register("reset", middleWareLoggedIn(handlerReset)); err != nil {
 		fmt.Printf("The command could not be registeres\n")
 		os.Exit(1)
 	}
@@ -456,14 +647,142 @@ func main() {
 		os.Exit(1)
 	}
 
-	if len(os.Args) < 2 {
-		fmt.Printf("No commando to run\n")
+	if err := gatorCommands.register("read", middleWareLoggedIn(handlerRead)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("unread", middleWareLoggedIn(handlerUnread)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("markread", middleWareLoggedIn(handlerMarkRead)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("star", middleWareLoggedIn(handlerStar)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("unstar", middleWareLoggedIn(handlerUnstar)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("starred", middleWareLoggedIn(handlerStarred)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("prune", middleWareLoggedIn(handlerPrune)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("search", middleWareLoggedIn(handlerSearch)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("tag", middleWareLoggedIn(handlerTag)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("rename-follow", middleWareLoggedIn(handlerRenameFollow)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("rule", middleWareLoggedIn(handlerRule)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("dismiss", middleWareLoggedIn(handlerDismiss)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("train", middleWareLoggedIn(handlerTrain)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("open", middleWareLoggedIn(handlerOpen)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
 		os.Exit(1)
 	}
 
-	if err := gatorCommands.run(&gatorState, command{name: os.Args[1], args: os.Args[1:]}); err != nil {
-		fmt.Printf("Error while executing the command: %v\n", err)
+	if err := gatorCommands.register("tui", middleWareLoggedIn(handlerTui)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("fulltext", middleWareLoggedIn(handlerFullText)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("import", middleWareLoggedIn(handlerImport)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("export", middleWareLoggedIn(handlerExport)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("removefeed", middleWareLoggedIn(handlerRemoveFeed)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("editfeed", middleWareLoggedIn(handlerEditFeed)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
 		os.Exit(1)
 	}
 
+	if err := gatorCommands.register("transferfeed", middleWareLoggedIn(handlerTransferFeed)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("deleteuser", middleWareLoggedIn(handlerDeleteUser)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("renameuser", middleWareLoggedIn(handlerRenameUser)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("wipeuser", middleWareLoggedIn(handlerWipeUser)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("pause", middleWareLoggedIn(handlerPause)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if err := gatorCommands.register("resume", middleWareLoggedIn(handlerResume)); err != nil {
+		fmt.Printf("The command could not be registeres\n")
+		os.Exit(1)
+	}
+
+	if len(args) < 1 {
+		exitWithError(output.format, "No commando to run")
+	}
+
+	if err := gatorCommands.run(&gatorState, command{name: args[0], args: args}); err != nil {
+		exitWithError(output.format, fmt.Sprintf("Error while executing the command: %v", err))
+	}
+
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+// defaultBrowser opens URLs when $BROWSER is not set.
+const defaultBrowser = "xdg-open"
+
+func handlerOpen(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 {
+		return errors.New("the command open expect one argument: <post-id|index>")
+	}
+
+	post, err := s.getPost(user, cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	if err := openURL(post.Url); err != nil {
+		return fmt.Errorf("the post could not be opened: %v", err)
+	}
+
+	err = s.db.MarkPostOpened(context.Background(),
+		database.MarkPostOpenedParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the post could not be marked as read: %v", err)
+	}
+
+	fmt.Printf("Opened: %s\n", stripControl(post.Title))
+	return nil
+}
+
+// openURL launches the browser without waiting for it to exit.
+func openURL(url string) error {
+	browser := browserCommand(os.Getenv("BROWSER"))
+	browser = append(browser, url)
+
+	process := exec.Command(browser[0], browser[1:]...)
+	if err := process.Start(); err != nil {
+		return err
+	}
+
+	return process.Process.Release()
+}
+
+// browserCommand returns the command line of the browser. $BROWSER may hold
+// several commands separated by colons, of which the first is used.
+func browserCommand(env string) []string {
+	for _, candidate := range strings.Split(env, ":") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{defaultBrowser}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBrowserCommand(t *testing.T) {
+	cases := []struct {
+		name string
+		env  string
+		want []string
+	}{
+		{name: "Unset", env: "", want: []string{"xdg-open"}},
+		{name: "Single", env: "firefox", want: []string{"firefox"}},
+		{name: "With arguments", env: "firefox --new-tab", want: []string{"firefox", "--new-tab"}},
+		{name: "List", env: ":w3m:lynx", want: []string{"w3m"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := browserCommand(c.env); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("browserCommand(%q) = %v, want %v", c.env, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseBrowseIndex(t *testing.T) {
+	cases := []struct {
+		ref   string
+		index int
+		ok    bool
+	}{
+		{ref: "3", index: 3, ok: true},
+		{ref: "#12", index: 12, ok: true},
+		{ref: "0", ok: false},
+		{ref: "12345678", ok: false},
+		{ref: "#12345678", index: 12345678, ok: true},
+		{ref: "a1b2c3d4", ok: false},
+	}
+
+	for _, c := range cases {
+		index, ok := parseBrowseIndex(c.ref)
+		if index != c.index || ok != c.ok {
+			t.Errorf("parseBrowseIndex(%q) = %d, %v, want %d, %v", c.ref, index, ok, c.index, c.ok)
+		}
+	}
+}
+
+func TestIsShortID(t *testing.T) {
+	cases := map[string]bool{
+		"a1b2c3d4":                              true,
+		"A1B2C3D4":                              true,
+		"a1b":                                   false,
+		"a1b2c3d4-e5f6":                         true,
+		"zz12abcd":                              false,
+		"8c7f5a4e-1f2b-4c3d-9e8f-7a6b5c4d3e2f0": false,
+	}
+
+	for ref, want := range cases {
+		if got := isShortID(ref); got != want {
+			t.Errorf("isShortID(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
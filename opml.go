@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const (
+	importUsage = "usage: import opml <file> [--dry-run]"
+	exportUsage = "usage: export opml [file]"
+)
+
+// opmlDocument is an OPML 2.0 subscription list.
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    opmlHead `xml:"head"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+	OwnerName   string `xml:"ownerName,omitempty"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlOutline is either a feed, with an xmlUrl, or a folder of outlines.
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlEntry is a feed of an OPML file with the folders holding it.
+type opmlEntry struct {
+	name string
+	url  string
+	tags []string
+}
+
+func (o opmlOutline) name() string {
+	if o.Text != "" {
+		return o.Text
+	}
+	return o.Title
+}
+
+// opmlEntries flattens the outlines into feeds tagged with the names of their
+// folders. Feeds listed in several folders are merged, and the outlines
+// that are neither feeds nor folders are reported as invalid.
+func opmlEntries(outlines []opmlOutline) ([]opmlEntry, []string, []string) {
+	entries := []opmlEntry{}
+	duplicates := []string{}
+	invalid := []string{}
+
+	var walk func(outlines []opmlOutline, folders []string)
+	walk = func(outlines []opmlOutline, folders []string) {
+		for _, outline := range outlines {
+			if outline.XMLURL == "" {
+				if len(outline.Outlines) == 0 {
+					invalid = append(invalid, fmt.Sprintf("%q: no xmlUrl", outline.name()))
+					continue
+				}
+				folder := folders
+				if outline.name() != "" {
+					folder = append(slices.Clip(folders), outline.name())
+				}
+				walk(outline.Outlines, folder)
+				continue
+			}
+
+			feedURL, err := url.Parse(outline.XMLURL)
+			if err != nil || (feedURL.Scheme != "http" && feedURL.Scheme != "https") || feedURL.Host == "" {
+				invalid = append(invalid, fmt.Sprintf("%q: invalid xmlUrl %s", outline.name(), outline.XMLURL))
+				continue
+			}
+
+			i := slices.IndexFunc(entries, func(e opmlEntry) bool { return e.url == outline.XMLURL })
+			if i >= 0 {
+				duplicates = append(duplicates, fmt.Sprintf("%s: listed more than once in the file", outline.XMLURL))
+				for _, tag := range folders {
+					if !slices.Contains(entries[i].tags, tag) {
+						entries[i].tags = append(entries[i].tags, tag)
+					}
+				}
+				continue
+			}
+
+			name := outline.name()
+			if name == "" {
+				name = outline.XMLURL
+			}
+			entries = append(entries, opmlEntry{name: name, url: outline.XMLURL, tags: append([]string{}, folders...)})
+		}
+	}
+	walk(outlines, nil)
+
+	return entries, duplicates, invalid
+}
+
+// handlerImport imports subscription lists from other readers.
+func handlerImport(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 || cmd.args[1] != "opml" {
+		return errors.New(importUsage)
+	}
+
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without saving it")
+
+	args, err := parseFlags(fs, cmd.args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return errors.New(importUsage)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("the file could not be opened: %v", err)
+	}
+	defer file.Close()
+
+	doc := opmlDocument{}
+	if err := xml.NewDecoder(file).Decode(&doc); err != nil {
+		return fmt.Errorf("the file is not valid OPML: %v", err)
+	}
+
+	entries, duplicates, invalid := opmlEntries(doc.Body.Outlines)
+	created, followed := 0, 0
+	createdLabel, followedLabel := "Created feed", "    Followed"
+	if *dryRun {
+		createdLabel, followedLabel = "Would create feed", "     Would follow"
+	}
+
+	err = s.withTx(func(tx *state) error {
+		for _, entry := range entries {
+			feedCreated, feedFollowed, err := tx.importFeed(user, entry)
+			if err != nil {
+				return fmt.Errorf("%s could not be imported: %v", entry.url, err)
+			}
+
+			if feedCreated {
+				created++
+				fmt.Printf("%s: %s (%s)\n", createdLabel, entry.name, entry.url)
+			}
+			if feedFollowed {
+				followed++
+				fmt.Printf("%s: %s\n", followedLabel, entry.url)
+			} else {
+				duplicates = append(duplicates, fmt.Sprintf("%s: already followed", entry.url))
+			}
+		}
+
+		if *dryRun {
+			return errRollback
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errRollback) {
+		return err
+	}
+
+	for _, duplicate := range duplicates {
+		fmt.Printf("   Duplicate: %s\n", duplicate)
+	}
+	for _, entry := range invalid {
+		fmt.Printf("     Invalid: %s\n", entry)
+	}
+
+	if *dryRun {
+		fmt.Printf("\n%d feeds would be followed (%d created), %d duplicates, %d invalid entries\n", followed, created, len(duplicates), len(invalid))
+		fmt.Println("Dry run: nothing was saved")
+	} else {
+		fmt.Printf("\n%d feeds followed (%d created), %d duplicates, %d invalid entries\n", followed, created, len(duplicates), len(invalid))
+	}
+	return nil
+}
+
+// importFeed creates the feed of an OPML entry when it is missing, follows
+// it and tags it with the folders of the entry. It reports whether the feed
+// was created and whether it was followed.
+func (s *state) importFeed(user database.User, entry opmlEntry) (bool, bool, error) {
+	created := false
+	feed, err := s.findFeedByURL(entry.url)
+	if errors.Is(err, sql.ErrNoRows) {
+		feed, err = s.db.CreateFeed(context.Background(),
+			database.CreateFeedParams{
+				ID:     uuid.New(),
+				Name:   entry.name,
+				Url:    s.normalizeLink(entry.url),
+				UserID: user.ID,
+			},
+		)
+		created = true
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	followed := false
+	feedFollow, err := s.db.GetFeedFollowByURL(context.Background(),
+		database.GetFeedFollowByURLParams{
+			UserID: user.ID,
+			Url:    feed.Url,
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		feedFollow.ID, err = s.followImportedFeed(user, feed, entry.name)
+		followed = true
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	for _, name := range entry.tags {
+		tag, err := s.getOrCreateTag(user, name)
+		if err != nil {
+			return false, false, err
+		}
+
+		err = s.db.AddFeedFollowTag(context.Background(),
+			database.AddFeedFollowTagParams{
+				FeedFollowID: feedFollow.ID,
+				TagID:        tag.ID,
+			},
+		)
+		if err != nil {
+			return false, false, err
+		}
+	}
+
+	return created, followed, nil
+}
+
+// followImportedFeed follows a feed for the user, keeping the name given by
+// the other reader to a feed already known under another name.
+func (s *state) followImportedFeed(user database.User, feed database.Feed, name string) (uuid.UUID, error) {
+	rows, err := s.db.CreateFeedFollow(context.Background(),
+		database.CreateFeedFollowParams{
+			ID:     uuid.New(),
+			FeedID: feed.ID,
+			UserID: user.ID,
+		},
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if name != feed.Name && name != feed.Url {
+		_, err = s.db.SetFeedFollowTitle(context.Background(),
+			database.SetFeedFollowTitleParams{
+				Title:  sql.NullString{String: name, Valid: true},
+				UserID: user.ID,
+				Url:    feed.Url,
+			},
+		)
+	}
+	return rows[0].ID, err
+}
+
+// handlerExport writes the feeds followed by the user as OPML, to stdout or
+// to a file.
+func handlerExport(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 || len(cmd.args) > 3 || cmd.args[1] != "opml" {
+		return errors.New(exportUsage)
+	}
+
+	feedFollows, err := s.db.GetFeedFollowForUser(context.Background(),
+		database.GetFeedFollowForUserParams{UserID: user.ID},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the followed feeds could not be loaded: %v", err)
+	}
+
+	data, err := xml.MarshalIndent(buildOPML(user.Name, feedFollows, time.Now()), "", "  ")
+	if err != nil {
+		return fmt.Errorf("the OPML document could not be encoded: %v", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	if len(cmd.args) == 2 {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(cmd.args[2], data, 0644); err != nil {
+		return fmt.Errorf("the file could not be written: %v", err)
+	}
+
+	fmt.Printf("%d feeds exported to %s\n", len(feedFollows), cmd.args[2])
+	return nil
+}
+
+// buildOPML lists the followed feeds under the names given by the user. Each
+// tag becomes a folder, so a feed with several tags is listed in each of
+// them, and the feeds without tags stay at the top level.
+func buildOPML(userName string, feedFollows []database.GetFeedFollowForUserRow, now time.Time) opmlDocument {
+	doc := opmlDocument{
+		Version: "2.0",
+		Head: opmlHead{
+			Title:       "gator subscriptions of " + userName,
+			DateCreated: now.Format(time.RFC1123Z),
+			OwnerName:   userName,
+		},
+	}
+
+	folders := []opmlOutline{}
+	for _, feedFollow := range feedFollows {
+		outline := opmlOutline{
+			Text:   feedFollow.FeedName,
+			Title:  feedFollow.FeedName,
+			Type:   "rss",
+			XMLURL: feedFollow.FeedUrl,
+		}
+
+		if len(feedFollow.Tags) == 0 {
+			doc.Body.Outlines = append(doc.Body.Outlines, outline)
+			continue
+		}
+
+		for _, tag := range feedFollow.Tags {
+			i := slices.IndexFunc(folders, func(o opmlOutline) bool { return o.Text == tag })
+			if i < 0 {
+				folders = append(folders, opmlOutline{Text: tag, Title: tag})
+				i = len(folders) - 1
+			}
+			folders[i].Outlines = append(folders[i].Outlines, outline)
+		}
+	}
+
+	slices.SortFunc(folders, func(a, b opmlOutline) int { return strings.Compare(a.Text, b.Text) })
+	doc.Body.Outlines = append(folders, doc.Body.Outlines...)
+	return doc
+}
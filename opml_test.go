@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const sampleOPML = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+    <outline text="Tech">
+      <outline text="Lobsters" type="rss" xmlUrl="https://lobste.rs/rss"/>
+      <outline title="Dev">
+        <outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+      </outline>
+    </outline>
+    <outline text="No URL"/>
+    <outline text="Bad URL" xmlUrl="ftp://example.com/feed"/>
+    <outline type="rss" xmlUrl="https://example.com/rss"/>
+  </body>
+</opml>`
+
+func TestOPMLEntries(t *testing.T) {
+	doc := opmlDocument{}
+	if err := xml.Unmarshal([]byte(sampleOPML), &doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, duplicates, invalid := opmlEntries(doc.Body.Outlines)
+
+	wantEntries := []opmlEntry{
+		{name: "Go Blog", url: "https://go.dev/blog/feed.atom", tags: []string{"Tech", "Dev"}},
+		{name: "Lobsters", url: "https://lobste.rs/rss", tags: []string{"Tech"}},
+		{name: "https://example.com/rss", url: "https://example.com/rss", tags: []string{}},
+	}
+	wantDuplicates := []string{"https://go.dev/blog/feed.atom: listed more than once in the file"}
+	wantInvalid := []string{`"No URL": no xmlUrl`, `"Bad URL": invalid xmlUrl ftp://example.com/feed`}
+
+	if !reflect.DeepEqual(entries, wantEntries) {
+		t.Errorf("entries = %v, want %v", entries, wantEntries)
+	}
+	if !reflect.DeepEqual(duplicates, wantDuplicates) {
+		t.Errorf("duplicates = %q, want %q", duplicates, wantDuplicates)
+	}
+	if !reflect.DeepEqual(invalid, wantInvalid) {
+		t.Errorf("invalid = %q, want %q", invalid, wantInvalid)
+	}
+}
+
+func TestBuildOPML(t *testing.T) {
+	feedFollows := []database.GetFeedFollowForUserRow{
+		{FeedName: "Go", FeedUrl: "https://go.dev/blog/feed.atom", Tags: []string{"news", "dev"}},
+		{FeedName: "My Blog", FeedUrl: "https://blog.example.com/rss"},
+		{FeedName: "Lobsters", FeedUrl: "https://lobste.rs/rss", Tags: []string{"news"}},
+	}
+	now := time.Date(2025, 5, 4, 10, 30, 0, 0, time.UTC)
+
+	data, err := xml.MarshalIndent(buildOPML("ana", feedFollows, now), "", "  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `<opml version="2.0">
+  <head>
+    <title>gator subscriptions of ana</title>
+    <dateCreated>Sun, 04 May 2025 10:30:00 +0000</dateCreated>
+    <ownerName>ana</ownerName>
+  </head>
+  <body>
+    <outline text="dev" title="dev">
+      <outline text="Go" title="Go" type="rss" xmlUrl="https://go.dev/blog/feed.atom"></outline>
+    </outline>
+    <outline text="news" title="news">
+      <outline text="Go" title="Go" type="rss" xmlUrl="https://go.dev/blog/feed.atom"></outline>
+      <outline text="Lobsters" title="Lobsters" type="rss" xmlUrl="https://lobste.rs/rss"></outline>
+    </outline>
+    <outline text="My Blog" title="My Blog" type="rss" xmlUrl="https://blog.example.com/rss"></outline>
+  </body>
+</opml>`
+
+	if string(data) != want {
+		t.Errorf("buildOPML() =\n%s\nwant\n%s", data, want)
+	}
+
+	// The export must be readable by the import.
+	doc := opmlDocument{}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("the export could not be parsed: %v", err)
+	}
+	entries, duplicates, invalid := opmlEntries(doc.Body.Outlines)
+	if len(entries) != 3 || len(duplicates) != 1 || len(invalid) != 0 {
+		t.Errorf("round trip: %d entries, %d duplicates, %d invalid", len(entries), len(duplicates), len(invalid))
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// outputFormat selects how the listings of users, feeds, following and
+// browse are printed.
+type outputFormat string
+
+const (
+	outputText  outputFormat = "text"
+	outputJSON  outputFormat = "json"
+	outputJSONL outputFormat = "jsonl"
+	outputCSV   outputFormat = "csv"
+	// outputTemplate prints each row with the template of --format.
+	outputTemplate outputFormat = "template"
+)
+
+const globalUsage = "gator [--output text|json|jsonl|csv] [--format TEMPLATE] <command> [args...]"
+
+// outputOptions are the global options selecting the output of the listings.
+type outputOptions struct {
+	format   outputFormat
+	template *template.Template
+}
+
+// UserRow is a user as printed by the users command.
+type UserRow struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+}
+
+// FeedRow is a feed as printed by the feeds command.
+type FeedRow struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	CreatedBy string `json:"created_by"`
+	Paused    bool   `json:"paused"`
+}
+
+// FollowRow is a followed feed as printed by the following command.
+type FollowRow struct {
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	User          string     `json:"user"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	Tags          []string   `json:"tags"`
+	Paused        bool       `json:"paused"`
+	PausedUntil   *time.Time `json:"paused_until"`
+	FeedPaused    bool       `json:"feed_paused"`
+}
+
+// PostRow is a post as printed by browse and unread.
+type PostRow struct {
+	ID          string     `json:"id"`
+	ShortID     string     `json:"short_id"`
+	Index       int        `json:"index"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Feed        string     `json:"feed"`
+	Author      string     `json:"author"`
+	Categories  []string   `json:"categories"`
+	PublishedAt *time.Time `json:"published_at"`
+	Read        bool       `json:"read"`
+	Starred     bool       `json:"starred"`
+	Highlighted bool       `json:"highlighted"`
+	Relevance   *float64   `json:"relevance"`
+	Sources     []string   `json:"sources"`
+	Description string     `json:"description"`
+	FullContent string     `json:"full_content"`
+}
+
+func parseOutputFormat(value string) (outputFormat, error) {
+	switch format := outputFormat(strings.ToLower(value)); format {
+	case outputText, outputJSON, outputJSONL, outputCSV:
+		return format, nil
+	default:
+		return outputText, fmt.Errorf("unknown output format %s, use text, json, jsonl or csv", value)
+	}
+}
+
+// parseGlobalOptions reads the options given before the command name and
+// returns the remaining arguments, starting with the command name.
+func parseGlobalOptions(args []string) (outputOptions, []string, error) {
+	opts := outputOptions{format: outputText}
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if name != "output" && name != "o" && name != "format" {
+			return opts, nil, fmt.Errorf("unknown option %s, usage: %s", args[0], globalUsage)
+		}
+
+		if !hasValue {
+			if len(args) < 2 {
+				return opts, nil, fmt.Errorf("the option %s expect a value", args[0])
+			}
+			value = args[1]
+			args = args[1:]
+		}
+		args = args[1:]
+
+		if name == "format" {
+			tmpl, err := parseRowTemplate(value)
+			if err != nil {
+				return opts, nil, err
+			}
+			opts.template = tmpl
+			continue
+		}
+
+		format, err := parseOutputFormat(value)
+		if err != nil {
+			return opts, nil, err
+		}
+		opts.format = format
+	}
+
+	if opts.template != nil {
+		if opts.format != outputText {
+			return opts, nil, errors.New("the options --output and --format cannot be combined")
+		}
+		opts.format = outputTemplate
+	}
+	return opts, args, nil
+}
+
+// exitWithError prints the error of a command and stops gator. In JSON modes
+// the error is printed as a JSON object so scripts can parse it.
+func exitWithError(format outputFormat, message string) {
+	if format == outputJSON || format == outputJSONL {
+		json.NewEncoder(os.Stdout).Encode(map[string]string{"error": message})
+	} else {
+		fmt.Printf("%s\n", message)
+	}
+	os.Exit(1)
+}
+
+// printRows prints the rows of a listing in a structured format: a JSON
+// array, one JSON object per line, CSV with a header named after the JSON
+// fields or a line per row rendered by the --format template.
+func printRows[T any](w io.Writer, opts outputOptions, rows []T) error {
+	switch opts.format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case outputJSONL:
+		encoder := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputCSV:
+		return writeCSV(w, rows)
+	case outputTemplate:
+		for _, row := range rows {
+			if err := opts.template.Execute(w, row); err != nil {
+				return fmt.Errorf("the format could not be applied: %v", err)
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	default:
+		return fmt.Errorf("the output format %s cannot print rows", opts.format)
+	}
+}
+
+func writeCSV[T any](w io.Writer, rows []T) error {
+	writer := csv.NewWriter(w)
+	rowType := reflect.TypeFor[T]()
+
+	header := make([]string, rowType.NumField())
+	for i := range header {
+		header[i], _, _ = strings.Cut(rowType.Field(i).Tag.Get("json"), ",")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		value := reflect.ValueOf(row)
+		record := make([]string, value.NumField())
+		for i := range record {
+			record[i] = csvValue(value.Field(i))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvValue formats a field of a row: times as RFC 3339, lists separated by
+// semicolons and missing values as empty cells.
+func csvValue(value reflect.Value) string {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, ";")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// nullTime converts a nullable time to a pointer, nil when missing.
+func nullTime(valid bool, t time.Time) *time.Time {
+	if !valid {
+		return nil
+	}
+	return &t
+}
+
+// splitList splits a list stored as text, returning an empty list for an
+// empty text.
+func splitList(text, separator string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, separator)
+}
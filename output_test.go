@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseGlobalOptions(t *testing.T) {
+	cases := []struct {
+		name    string
+		args    []string
+		format  outputFormat
+		rest    []string
+		wantErr bool
+	}{
+		{name: "None", args: []string{"users"}, format: outputText, rest: []string{"users"}},
+		{name: "Separate value", args: []string{"--output", "json", "users"}, format: outputJSON, rest: []string{"users"}},
+		{name: "Inline value", args: []string{"--output=csv", "feeds"}, format: outputCSV, rest: []string{"feeds"}},
+		{name: "Short", args: []string{"-o", "JSONL", "browse", "5"}, format: outputJSONL, rest: []string{"browse", "5"}},
+		{name: "Command flags untouched", args: []string{"browse", "--tag", "go"}, format: outputText, rest: []string{"browse", "--tag", "go"}},
+		{name: "Unknown format", args: []string{"--output", "xml", "users"}, wantErr: true},
+		{name: "Unknown option", args: []string{"--verbose", "users"}, wantErr: true},
+		{name: "Missing value", args: []string{"--output"}, wantErr: true},
+		{name: "Template", args: []string{"--format", "{{.Name}}", "users"}, format: outputTemplate, rest: []string{"users"}},
+		{name: "Invalid template", args: []string{"--format", "{{.Name", "users"}, wantErr: true},
+		{name: "Template and output", args: []string{"--output", "json", "--format", "{{.Name}}", "users"}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts, rest, err := parseGlobalOptions(c.args)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.format != c.format || !reflect.DeepEqual(rest, c.rest) {
+				t.Errorf("parseGlobalOptions(%q) = %s, %q, want %s, %q", c.args, opts.format, rest, c.format, c.rest)
+			}
+		})
+	}
+}
+
+func TestPrintRows(t *testing.T) {
+	fetched := time.Date(2025, 5, 4, 10, 30, 0, 0, time.UTC)
+	pausedUntil := time.Date(2025, 5, 11, 0, 0, 0, 0, time.UTC)
+	rows := []FollowRow{
+		{Name: "Go Blog", URL: "https://go.dev/blog/feed.atom", User: "ana", LastFetchedAt: &fetched, Tags: []string{"dev", "go"}},
+		{Name: "Lobsters", URL: "https://lobste.rs/rss", User: "ana", Tags: []string{}, Paused: true, PausedUntil: &pausedUntil},
+	}
+
+	cases := []struct {
+		format outputFormat
+		want   string
+	}{
+		{
+			format: outputJSONL,
+			want: `{"name":"Go Blog","url":"https://go.dev/blog/feed.atom","user":"ana","last_fetched_at":"2025-05-04T10:30:00Z","tags":["dev","go"],"paused":false,"paused_until":null,"feed_paused":false}
+{"name":"Lobsters","url":"https://lobste.rs/rss","user":"ana","last_fetched_at":null,"tags":[],"paused":true,"paused_until":"2025-05-11T00:00:00Z","feed_paused":false}
+`,
+		},
+		{
+			format: outputCSV,
+			want: `name,url,user,last_fetched_at,tags,paused,paused_until,feed_paused
+Go Blog,https://go.dev/blog/feed.atom,ana,2025-05-04T10:30:00Z,dev;go,false,,false
+Lobsters,https://lobste.rs/rss,ana,,,true,2025-05-11T00:00:00Z,false
+`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printRows(&buf, outputOptions{format: c.format}, rows); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != c.want {
+				t.Errorf("printRows() =\n%s\nwant\n%s", buf.String(), c.want)
+			}
+		})
+	}
+}
+
+func TestPrintRowsEmptyJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printRows(&buf, outputOptions{format: outputJSON}, []UserRow{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("printRows() = %q, want %q", buf.String(), "[]\n")
+	}
+}
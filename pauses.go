@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const (
+	pauseUsage  = "pause <feed> [--for <duration> | --until <date>] [--global]"
+	resumeUsage = "resume <feed> [--global]"
+
+	pauseTimeLayout = "2006-01-02 15:04"
+)
+
+// handlerPause hides the posts of a followed feed for the user, until a date
+// or until the feed is resumed. With --global the owner of the feed stops agg
+// from fetching it for everyone.
+func handlerPause(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	duration := fs.String("for", "", "pause for a duration like 3d or 2w")
+	until := fs.String("until", "", "pause until a date (YYYY-MM-DD)")
+	global := fs.Bool("global", false, "stop fetching the feed for every user")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 || (*duration != "" && *until != "") {
+		return fmt.Errorf("usage: %s", pauseUsage)
+	}
+
+	if *global {
+		if *duration != "" || *until != "" {
+			return errors.New("a global pause lasts until the feed is resumed, --for and --until only apply to your own pause")
+		}
+		return s.setFeedPaused(user, args[0], true)
+	}
+
+	pausedUntil, err := pauseEnd(*duration, *until, time.Now())
+	if err != nil {
+		return err
+	}
+
+	candidates, err := s.followedFeedCandidates(user)
+	if err != nil {
+		return err
+	}
+
+	feed, err := s.resolveFeedToChange(args[0], candidates)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.db.PauseFeedFollow(context.Background(),
+		database.PauseFeedFollowParams{
+			PausedUntil: pausedUntil,
+			UserID:      user.ID,
+			FeedID:      feed.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the feed could not be paused: %v", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("you are not following %s", feed.Name)
+	}
+
+	if pausedUntil.Valid {
+		fmt.Printf("The posts of %s are hidden until %s\n", feed.Name, pausedUntil.Time.Format(pauseTimeLayout))
+	} else {
+		fmt.Printf("The posts of %s are hidden until you resume it\n", feed.Name)
+	}
+	return nil
+}
+
+func handlerResume(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	global := fs.Bool("global", false, "fetch the feed again for every user")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s", resumeUsage)
+	}
+
+	if *global {
+		return s.setFeedPaused(user, args[0], false)
+	}
+
+	candidates, err := s.followedFeedCandidates(user)
+	if err != nil {
+		return err
+	}
+
+	feed, err := s.resolveFeed(args[0], candidates)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.db.ResumeFeedFollow(context.Background(),
+		database.ResumeFeedFollowParams{
+			UserID: user.ID,
+			FeedID: feed.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the feed could not be resumed: %v", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("%s is not paused", feed.Name)
+	}
+
+	fmt.Printf("The posts of %s are shown again\n", feed.Name)
+	return nil
+}
+
+// setFeedPaused pauses or resumes the fetching of a feed for every user.
+// Only the owner of the feed may do it.
+func (s *state) setFeedPaused(user database.User, ref string, paused bool) error {
+	candidates, err := s.allFeedCandidates()
+	if err != nil {
+		return err
+	}
+
+	feed, err := s.resolveFeedToChange(ref, candidates)
+	if err != nil {
+		return err
+	}
+
+	if feed.UserID != user.ID {
+		return fmt.Errorf("the feed %s belongs to another user, only its owner can pause it for everyone", feed.Name)
+	}
+
+	if paused == feed.PausedAt.Valid {
+		if paused {
+			return fmt.Errorf("%s is already paused", feed.Name)
+		}
+		return fmt.Errorf("%s is not paused", feed.Name)
+	}
+
+	_, err = s.db.SetFeedPaused(context.Background(),
+		database.SetFeedPausedParams{
+			Paused: paused,
+			ID:     feed.ID,
+			UserID: user.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the feed could not be updated: %v", err)
+	}
+
+	if paused {
+		fmt.Printf("%s is paused: agg will not fetch it until it is resumed\n", feed.Name)
+	} else {
+		fmt.Printf("%s is resumed: agg will fetch it again\n", feed.Name)
+	}
+	return nil
+}
+
+// pauseEnd returns the end of a pause given by --for or --until. Without
+// either the pause lasts until the feed is resumed.
+func pauseEnd(duration, until string, now time.Time) (sql.NullTime, error) {
+	switch {
+	case duration != "":
+		length, err := parseAge(duration)
+		if err != nil {
+			return sql.NullTime{}, err
+		}
+		if length <= 0 {
+			return sql.NullTime{}, fmt.Errorf("the pause duration %s is not positive", duration)
+		}
+		return sql.NullTime{Time: now.Add(length), Valid: true}, nil
+
+	case until != "":
+		end, err := time.ParseInLocation(time.DateOnly, until, time.Local)
+		if err != nil {
+			end, err = time.Parse(time.RFC3339, until)
+		}
+		if err != nil {
+			return sql.NullTime{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", until)
+		}
+		if !end.After(now) {
+			return sql.NullTime{}, fmt.Errorf("the date %s is in the past", until)
+		}
+		return sql.NullTime{Time: end, Valid: true}, nil
+	}
+
+	return sql.NullTime{}, nil
+}
+
+// pauseState describes the pause of a followed feed at now, in the time zone
+// of now, or returns an empty string when the feed is not paused.
+func pauseState(pausedAt, pausedUntil sql.NullTime, now time.Time) string {
+	if !isPaused(pausedAt, pausedUntil, now) {
+		return ""
+	}
+	if pausedUntil.Valid {
+		return "until " + pausedUntil.Time.In(now.Location()).Format(pauseTimeLayout)
+	}
+	return "until resumed"
+}
+
+// isPaused reports whether a pause started at pausedAt is still running. The
+// pause columns hold instants, so they compare with now in any time zone.
+func isPaused(pausedAt, pausedUntil sql.NullTime, now time.Time) bool {
+	return pausedAt.Valid && (!pausedUntil.Valid || pausedUntil.Time.After(now))
+}
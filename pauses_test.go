@@ -0,0 +1,65 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestPauseEnd(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+
+	cases := []struct {
+		name     string
+		duration string
+		until    string
+		want     sql.NullTime
+		wantErr  bool
+	}{
+		{name: "Until resumed", want: sql.NullTime{}},
+		{name: "Days", duration: "7d", want: sql.NullTime{Time: now.Add(7 * 24 * time.Hour), Valid: true}},
+		{name: "Hours", duration: "12h", want: sql.NullTime{Time: now.Add(12 * time.Hour), Valid: true}},
+		{name: "Zero duration", duration: "0d", wantErr: true},
+		{name: "Invalid duration", duration: "soon", wantErr: true},
+		{name: "Date", until: "2026-11-02", want: sql.NullTime{Time: time.Date(2026, 11, 2, 0, 0, 0, 0, time.Local), Valid: true}},
+		{name: "Timestamp", until: "2026-10-20T08:00:00Z", want: sql.NullTime{Time: time.Date(2026, 10, 20, 8, 0, 0, 0, time.UTC), Valid: true}},
+		{name: "Past date", until: "2026-10-01", wantErr: true},
+		{name: "Invalid date", until: "next week", wantErr: true},
+	}
+
+	for _, c := range cases {
+		got, err := pauseEnd(c.duration, c.until, now)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: pauseEnd() error = %v, wantErr %v", c.name, err, c.wantErr)
+			continue
+		}
+		if got.Valid != c.want.Valid || !got.Time.Equal(c.want.Time) {
+			t.Errorf("%s: pauseEnd() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPauseState(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	pausedAt := sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+
+	cases := []struct {
+		name        string
+		pausedAt    sql.NullTime
+		pausedUntil sql.NullTime
+		want        string
+	}{
+		{name: "Not paused", want: ""},
+		{name: "Until resumed", pausedAt: pausedAt, want: "until resumed"},
+		{name: "Until a date", pausedAt: pausedAt, pausedUntil: sql.NullTime{Time: now.Add(48 * time.Hour), Valid: true}, want: "until 2026-10-21 12:00"},
+		{name: "Expired", pausedAt: pausedAt, pausedUntil: sql.NullTime{Time: now.Add(-time.Minute), Valid: true}, want: ""},
+		{name: "Other time zone", pausedAt: pausedAt, pausedUntil: sql.NullTime{Time: now.Add(time.Hour).In(time.FixedZone("UTC-5", -5*60*60)), Valid: true}, want: "until 2026-10-19 13:00"},
+		{name: "Expired in other time zone", pausedAt: pausedAt, pausedUntil: sql.NullTime{Time: now.Add(-time.Minute).In(time.FixedZone("UTC+5", 5*60*60)), Valid: true}, want: ""},
+	}
+
+	for _, c := range cases {
+		if got := pauseState(c.pausedAt, c.pausedUntil, now); got != c.want {
+			t.Errorf("%s: pauseState() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const (
+	defaultBrowseLimit = 10
+	// shortIDLength is the number of hexadecimal digits of a post ID shown
+	// by the listings and accepted by the commands that take a post.
+	shortIDLength = 8
+)
+
+// browseOptions selects the posts listed by browse and unread.
+type browseOptions struct {
+	unreadOnly   bool
+	includeMuted bool
+	byRelevance  bool
+	// collapseClusters shows each story published by several feeds once.
+	collapseClusters bool
+	// raw prints the descriptions as received instead of rendering them.
+	raw   bool
+	links linkStyle
+	tag   string
+	limit int
+}
+
+// parseBrowseOptions parses the arguments shared by browse and unread: an
+// optional limit and the --tag filter.
+func parseBrowseOptions(cmd command) (browseOptions, error) {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	tag := fs.String("tag", "", "only show posts of feeds with this tag")
+	showMuted := fs.Bool("show-muted", false, "also show the posts muted by a rule")
+	sortBy := fs.String("sort", "date", "order of the posts: date or relevance")
+	expand := fs.Bool("expand", false, "show every post of a story published by several feeds")
+	raw := fs.Bool("raw", false, "print the descriptions without rendering their HTML")
+	links := fs.String("links", string(linksFootnotes), "how to show links: footnotes or osc8")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return browseOptions{}, err
+	}
+
+	if len(args) > 1 {
+		return browseOptions{}, fmt.Errorf("the command %s expect zero or one argument", cmd.name)
+	}
+
+	if *sortBy != "date" && *sortBy != "relevance" {
+		return browseOptions{}, fmt.Errorf("unknown sort order %q: use date or relevance", *sortBy)
+	}
+
+	style, err := parseLinkStyle(*links)
+	if err != nil {
+		return browseOptions{}, err
+	}
+
+	opts := browseOptions{
+		tag:          *tag,
+		includeMuted: *showMuted,
+		byRelevance:  *sortBy == "relevance",
+		limit:        defaultBrowseLimit,
+
+		collapseClusters: !*expand,
+		raw:              *raw,
+		links:            style,
+	}
+
+	if len(args) == 1 {
+		limit, err := strconv.Atoi(args[0])
+		if err != nil || limit < 1 {
+			return browseOptions{}, fmt.Errorf("the limit must be a positive number: %s", args[0])
+		}
+		opts.limit = limit
+	}
+
+	return opts, nil
+}
+
+// showPosts prints the latest posts of the feeds followed by the user and
+// marks every displayed post as read.
+func (s *state) showPosts(user database.User, opts browseOptions) error {
+	posts, err := s.db.GetPostsForUser(context.Background(),
+		database.GetPostsForUserParams{
+			UserID:           user.ID,
+			UnreadOnly:       opts.unreadOnly,
+			IncludeMuted:     opts.includeMuted,
+			CollapseClusters: opts.collapseClusters,
+			Tag:              sql.NullString{String: opts.tag, Valid: opts.tag != ""},
+			ByRelevance:      opts.byRelevance,
+			Limit:            int32(opts.limit),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("posts could not be loaded from the database: %v", err)
+	}
+
+	if len(posts) == 0 && s.output.format == outputText {
+		fmt.Println("There are no posts to show")
+		return nil
+	}
+
+	if err := s.db.ClearBrowseIndexes(context.Background(), user.ID); err != nil {
+		return fmt.Errorf("the previous listing could not be cleared: %v", err)
+	}
+
+	rows := make([]PostRow, 0, len(posts))
+	for i, post := range posts {
+		index := i + 1
+		sources := []database.GetClusterSourcesForUserRow{}
+		if opts.collapseClusters && post.ClusterID.Valid {
+			sources, err = s.db.GetClusterSourcesForUser(context.Background(),
+				database.GetClusterSourcesForUserParams{
+					ClusterID: post.ClusterID,
+					UserID:    user.ID,
+				},
+			)
+
+			if err != nil {
+				return fmt.Errorf("the sources of the story could not be loaded: %v", err)
+			}
+		}
+
+		if s.output.format == outputText {
+			printPost(index, post, sources, opts)
+		} else {
+			rows = append(rows, newPostRow(index, post, sources))
+		}
+
+		err = s.db.SetBrowseIndex(context.Background(),
+			database.SetBrowseIndexParams{
+				UserID:      user.ID,
+				PostID:      post.ID,
+				BrowseIndex: int32(index),
+			},
+		)
+
+		if err != nil {
+			return fmt.Errorf("the post could not be indexed: %v", err)
+		}
+
+		if err := s.markShownPostRead(user, post, len(sources) > 0); err != nil {
+			return fmt.Errorf("the post could not be marked as read: %v", err)
+		}
+	}
+
+	if s.output.format != outputText {
+		return printRows(os.Stdout, s.output, rows)
+	}
+	return nil
+}
+
+// newPostRow converts a post of browse to the row printed by the structured
+// output formats.
+func newPostRow(index int, post database.GetPostsForUserRow, sources []database.GetClusterSourcesForUserRow) PostRow {
+	row := PostRow{
+		ID:          post.ID.String(),
+		ShortID:     shortID(post.ID),
+		Index:       index,
+		Title:       post.Title,
+		URL:         post.Url,
+		Feed:        post.FeedName,
+		Author:      post.Author,
+		Categories:  splitList(post.Categories, categorySeparator),
+		PublishedAt: nullTime(post.PublishedAt.Valid, post.PublishedAt.Time),
+		Read:        post.ReadAt.Valid,
+		Starred:     post.StarredAt.Valid,
+		Highlighted: post.HighlightedAt.Valid,
+		Sources:     []string{},
+		Description: post.Description,
+		FullContent: post.FullContent.String,
+	}
+
+	if post.Relevance.Valid {
+		row.Relevance = &post.Relevance.Float64
+	}
+	if len(sources) > 1 {
+		row.Sources = clusterFeedNames(sources)
+	}
+	return row
+}
+
+// markShownPostRead marks a displayed post as read, together with the rest
+// of its cluster when the cluster was collapsed into it.
+func (s *state) markShownPostRead(user database.User, post database.GetPostsForUserRow, wholeCluster bool) error {
+	if wholeCluster {
+		return s.db.MarkClusterRead(context.Background(),
+			database.MarkClusterReadParams{
+				ClusterID: post.ClusterID,
+				UserID:    user.ID,
+			},
+		)
+	}
+
+	return s.db.MarkPostRead(context.Background(),
+		database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		},
+	)
+}
+
+// printPost prints a post of browse at the given position of the listing.
+// The sources are the posts of the other feeds telling the same story, when
+// clusters are collapsed.
+func printPost(index int, post database.GetPostsForUserRow, sources []database.GetClusterSourcesForUserRow, opts browseOptions) {
+	status := "new"
+	if post.ReadAt.Valid {
+		status = "read"
+	}
+
+	title := stripControl(post.Title)
+	if post.HighlightedAt.Valid {
+		status += ", highlighted"
+		title = highlightStart + title + highlightStop
+	}
+
+	fmt.Printf("         ID: %s (#%d)\n", shortID(post.ID), index)
+	fmt.Printf("      Title: %s\n", title)
+	fmt.Printf("       Feed: %s\n", stripControl(post.FeedName))
+	if post.PublishedAt.Valid {
+		fmt.Printf("  Published: %s\n", post.PublishedAt.Time.Format(time.RFC1123))
+	}
+	fmt.Printf("     Status: %s\n", status)
+	if post.Relevance.Valid {
+		fmt.Printf("  Relevance: %.0f%%\n", post.Relevance.Float64*100)
+	}
+	if len(sources) > 1 {
+		fmt.Printf("    Sources: %d posts from %s\n", len(sources), stripControl(strings.Join(clusterFeedNames(sources), ", ")))
+	}
+	if post.FullContent.Valid {
+		printDescription("    Article: ", post.FullContent.String, opts)
+	} else {
+		printDescription("Description: ", post.Description, opts)
+	}
+	fmt.Printf("        URL: %s\n", stripControl(post.Url))
+	fmt.Printf("------------------\n\n")
+}
+
+// printDescription prints the description or the article of a post rendered
+// as text wrapped to the terminal, aligned after its label. The raw HTML is
+// printed without its control characters.
+func printDescription(label, description string, opts browseOptions) {
+	if opts.raw {
+		fmt.Printf("%s%s\n", label, stripControl(description))
+		return
+	}
+
+	indent := strings.Repeat(" ", len(label))
+	lines := renderHTML(description, renderOptions{width: terminalWidth() - len(label), links: opts.links})
+	if len(lines) == 0 {
+		fmt.Printf("%s\n", strings.TrimSpace(label))
+		return
+	}
+
+	fmt.Printf("%s%s\n", label, lines[0])
+	for _, line := range lines[1:] {
+		if line == "" {
+			fmt.Println()
+		} else {
+			fmt.Printf("%s%s\n", indent, line)
+		}
+	}
+}
+
+func clusterFeedNames(sources []database.GetClusterSourcesForUserRow) []string {
+	names := []string{}
+	for _, source := range sources {
+		if !slices.Contains(names, source.FeedName) {
+			names = append(names, source.FeedName)
+		}
+	}
+	return names
+}
+
+// shortID returns the abbreviated form of a post ID shown by the listings.
+func shortID(id uuid.UUID) string {
+	return id.String()[:shortIDLength]
+}
+
+// getPost loads the post referenced by a command argument. The reference is
+// either the position of the post in the last browse listing (3 or #3), a
+// short ID or a full post ID. Only the posts of followed feeds and the
+// starred posts of the user can be referenced.
+func (s *state) getPost(user database.User, ref string) (database.Post, error) {
+	if index, ok := parseBrowseIndex(ref); ok {
+		post, err := s.db.GetPostByBrowseIndex(context.Background(),
+			database.GetPostByBrowseIndexParams{
+				UserID:      user.ID,
+				BrowseIndex: int32(index),
+			},
+		)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Post{}, fmt.Errorf("there is no post #%d in the last listing", index)
+		}
+		if err != nil {
+			return database.Post{}, fmt.Errorf("the post could not be loaded: %v", err)
+		}
+
+		return post, nil
+	}
+
+	if postID, err := uuid.Parse(ref); err == nil {
+		post, err := s.db.GetPostByID(context.Background(),
+			database.GetPostByIDParams{
+				ID:     postID,
+				UserID: user.ID,
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Post{}, fmt.Errorf("the post %s was not found", ref)
+		}
+		if err != nil {
+			return database.Post{}, fmt.Errorf("the post could not be loaded: %v", err)
+		}
+
+		return post, nil
+	}
+
+	if !isShortID(ref) {
+		return database.Post{}, fmt.Errorf("invalid post reference %s", ref)
+	}
+
+	posts, err := s.db.GetPostsByIDPrefix(context.Background(),
+		database.GetPostsByIDPrefixParams{
+			Prefix: strings.ToLower(ref),
+			UserID: user.ID,
+		},
+	)
+	if err != nil {
+		return database.Post{}, fmt.Errorf("the post could not be loaded: %v", err)
+	}
+
+	switch len(posts) {
+	case 0:
+		return database.Post{}, fmt.Errorf("the post %s was not found", ref)
+	case 1:
+		return posts[0], nil
+	default:
+		return database.Post{}, fmt.Errorf("the post ID %s is ambiguous, use more digits", ref)
+	}
+}
+
+// parseBrowseIndex recognizes a position of the browse listing. Numbers as
+// long as a short ID are taken as IDs.
+func parseBrowseIndex(ref string) (int, bool) {
+	digits := strings.TrimPrefix(ref, "#")
+	if digits != ref || len(digits) < shortIDLength {
+		index, err := strconv.Atoi(digits)
+		if err == nil && index > 0 {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+// isShortID reports whether ref can be the prefix of a post ID.
+func isShortID(ref string) bool {
+	if len(ref) < 4 || len(ref) > 36 {
+		return false
+	}
+	for _, r := range strings.ToLower(ref) {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func handlerRead(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 {
+		return errors.New("the command read expect one argument")
+	}
+
+	post, err := s.getPost(user, cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.MarkPostOpened(context.Background(),
+		database.MarkPostOpenedParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the post could not be marked as read: %v", err)
+	}
+
+	fmt.Printf("Marked as read: %s\n", stripControl(post.Title))
+	return nil
+}
+
+func handlerUnread(s *state, cmd command, user database.User) error {
+	opts, err := parseBrowseOptions(cmd)
+	if err != nil {
+		return err
+	}
+	opts.unreadOnly = true
+
+	return s.showPosts(user, opts)
+}
+
+func handlerMarkRead(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	feedRef := fs.String("feed", "", "only mark the posts of this feed (name, ID or URL)")
+	all := fs.Bool("all", false, "mark the posts of every followed feed")
+	olderThan := fs.String("older-than", "", "only mark posts older than this age (e.g. 7d, 2w, 12h)")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 0 {
+		return errors.New("the command markread expect no positional argument")
+	}
+
+	if *feedRef != "" && *all {
+		return errors.New("the flags --feed and --all cannot be used together")
+	}
+
+	if *feedRef == "" && !*all && *olderThan == "" {
+		return errors.New("the command markread expect --feed <feed>, --all or --older-than <age>")
+	}
+
+	params := database.MarkPostsReadParams{UserID: user.ID}
+
+	if *feedRef != "" {
+		candidates, err := s.followedFeedCandidates(user)
+		if err != nil {
+			return err
+		}
+		feed, err := s.resolveFeed(*feedRef, candidates)
+		if err != nil {
+			return err
+		}
+		params.FeedUrl = sql.NullString{String: feed.Url, Valid: true}
+	}
+
+	if *olderThan != "" {
+		age, err := parseAge(*olderThan)
+		if err != nil {
+			return err
+		}
+		params.OlderThan = sql.NullTime{Time: time.Now().Add(-age), Valid: true}
+	}
+
+	count, err := s.db.MarkPostsRead(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("the posts could not be marked as read: %v", err)
+	}
+
+	fmt.Printf("%d posts marked as read\n", count)
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var (
+	// errNotConfirmed is returned when a destructive command was not confirmed.
+	errNotConfirmed = errors.New("cancelled, nothing was changed")
+	// errNoTerminal is returned by choose when there is nobody to ask.
+	errNoTerminal = errors.New("no terminal to ask on")
+)
+
+// confirm asks the user to confirm a destructive command unless --yes was
+// given. Without a terminal to ask on, the command must be run with --yes.
+func confirm(question string, yes bool) error {
+	if yes {
+		return nil
+	}
+
+	if !isInteractive() {
+		return errors.New("confirmation required: run the command again with --yes")
+	}
+
+	fmt.Printf("%s [y/N] ", question)
+	return readConfirmation(os.Stdin)
+}
+
+// readConfirmation accepts y or yes as answer, anything else cancels.
+func readConfirmation(r io.Reader) error {
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && answer == "" {
+		return errNotConfirmed
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errNotConfirmed
+	}
+}
+
+// confirmName asks the user to type a name back to confirm a destructive
+// command on someone else, unless --yes was given.
+func confirmName(question, name string, yes bool) error {
+	if yes {
+		return nil
+	}
+
+	if !isInteractive() {
+		return errors.New("confirmation required: run the command again with --yes")
+	}
+
+	fmt.Printf("%s Type %s to confirm: ", question, name)
+	return readName(os.Stdin, name)
+}
+
+// readName accepts only the expected name as answer.
+func readName(r io.Reader, name string) error {
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && answer == "" {
+		return errNotConfirmed
+	}
+
+	if strings.TrimSpace(answer) != name {
+		return errNotConfirmed
+	}
+	return nil
+}
+
+// choose asks the user to pick one of the options and returns its index.
+func choose(question string, options []string) (int, error) {
+	if !isInteractive() {
+		return 0, errNoTerminal
+	}
+
+	fmt.Println(question)
+	for i, option := range options {
+		fmt.Printf("  %d) %s\n", i+1, option)
+	}
+	fmt.Printf("Choose 1-%d: ", len(options))
+	return readChoice(os.Stdin, len(options))
+}
+
+// readChoice reads the number of an option between 1 and count.
+func readChoice(r io.Reader, count int) (int, error) {
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && answer == "" {
+		return 0, errNotConfirmed
+	}
+
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return 0, errNotConfirmed
+	}
+
+	choice, err := strconv.Atoi(answer)
+	if err != nil || choice < 1 || choice > count {
+		return 0, fmt.Errorf("%s is not a choice between 1 and %d", answer, count)
+	}
+	return choice - 1, nil
+}
+
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadConfirmation(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "Yes", input: "y\n", want: nil},
+		{name: "Long yes", input: "Yes\n", want: nil},
+		{name: "Spaces", input: "  YES  \n", want: nil},
+		{name: "No", input: "n\n", want: errNotConfirmed},
+		{name: "Empty answer", input: "\n", want: errNotConfirmed},
+		{name: "Other answer", input: "yep\n", want: errNotConfirmed},
+		{name: "End of input", input: "", want: errNotConfirmed},
+		{name: "No newline", input: "y", want: nil},
+	}
+
+	for _, c := range cases {
+		if got := readConfirmation(strings.NewReader(c.input)); got != c.want {
+			t.Errorf("%s: readConfirmation() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestReadName(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "Name", input: "alice\n", want: nil},
+		{name: "Spaces", input: "  alice \n", want: nil},
+		{name: "No newline", input: "alice", want: nil},
+		{name: "Yes", input: "y\n", want: errNotConfirmed},
+		{name: "Other case", input: "Alice\n", want: errNotConfirmed},
+		{name: "Empty answer", input: "\n", want: errNotConfirmed},
+		{name: "End of input", input: "", want: errNotConfirmed},
+	}
+
+	for _, c := range cases {
+		if got := readName(strings.NewReader(c.input), "alice"); got != c.want {
+			t.Errorf("%s: readName() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestReadChoice(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{name: "First", input: "1\n", want: 0},
+		{name: "Last", input: " 3 \n", want: 2},
+		{name: "Zero", input: "0\n", wantErr: true},
+		{name: "Too large", input: "4\n", wantErr: true},
+		{name: "Not a number", input: "two\n", wantErr: true},
+		{name: "Empty answer", input: "\n", wantErr: true},
+		{name: "End of input", input: "", wantErr: true},
+	}
+
+	for _, c := range cases {
+		got, err := readChoice(strings.NewReader(c.input), 3)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: readChoice() error = %v, wantErr %v", c.name, err, c.wantErr)
+			continue
+		}
+		if !c.wantErr && got != c.want {
+			t.Errorf("%s: readChoice() = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
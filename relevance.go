@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const (
+	classUninteresting = 0
+	classInteresting   = 1
+
+	// starredWeight makes a starred post count as much as this many opened
+	// posts when training.
+	starredWeight = 3
+)
+
+var (
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+	stopWords = map[string]bool{
+		"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+		"you": true, "all": true, "any": true, "can": true, "had": true, "her": true,
+		"was": true, "one": true, "our": true, "out": true, "has": true, "his": true,
+		"how": true, "its": true, "new": true, "now": true, "who": true, "why": true,
+		"with": true, "this": true, "that": true, "from": true, "they": true, "have": true,
+		"what": true, "when": true, "your": true, "will": true, "into": true, "more": true,
+		"about": true, "there": true, "their": true, "which": true, "would": true, "these": true,
+	}
+)
+
+// relevanceModel is a multinomial naive Bayes classifier that tells apart
+// the posts a user finds interesting (starred or opened) from the ones they
+// dismissed. It is stored as JSON in relevance_models.
+type relevanceModel struct {
+	Docs   [2]float64            `json:"docs"`
+	Totals [2]float64            `json:"totals"`
+	Tokens [2]map[string]float64 `json:"tokens"`
+}
+
+func newRelevanceModel() *relevanceModel {
+	return &relevanceModel{
+		Tokens: [2]map[string]float64{{}, {}},
+	}
+}
+
+// tokenize splits the text of a post into lowercase words, skipping HTML
+// tags, short words and stop words.
+func tokenize(text string) []string {
+	text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " "))
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := []string{}
+	for _, word := range words {
+		if len(word) < 3 || stopWords[word] {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// postTokens returns the features of a post: the words of its title and
+// description plus its feed, since some feeds are simply more interesting.
+func postTokens(title, description, feedURL string) []string {
+	tokens := tokenize(title + " " + description)
+	if feedURL != "" {
+		tokens = append(tokens, "feed:"+feedURL)
+	}
+	return tokens
+}
+
+func (m *relevanceModel) train(tokens []string, class int, weight float64) {
+	m.Docs[class] += weight
+	for _, token := range tokens {
+		m.Tokens[class][token] += weight
+		m.Totals[class] += weight
+	}
+}
+
+// trained reports whether the model saw examples of both classes.
+func (m *relevanceModel) trained() bool {
+	return m.Docs[classInteresting] > 0 && m.Docs[classUninteresting] > 0
+}
+
+// score returns the probability, between 0 and 1, that the user finds the
+// post interesting.
+func (m *relevanceModel) score(tokens []string) float64 {
+	vocabulary := float64(len(m.Tokens[classInteresting]))
+	for token := range m.Tokens[classUninteresting] {
+		if _, found := m.Tokens[classInteresting][token]; !found {
+			vocabulary++
+		}
+	}
+
+	allDocs := m.Docs[classInteresting] + m.Docs[classUninteresting]
+	logProbs := [2]float64{}
+	for class := range logProbs {
+		logProbs[class] = math.Log((m.Docs[class] + 1) / (allDocs + 2))
+		for _, token := range tokens {
+			logProbs[class] += math.Log((m.Tokens[class][token] + 1) / (m.Totals[class] + vocabulary + 1))
+		}
+	}
+
+	return 1 / (1 + math.Exp(logProbs[classUninteresting]-logProbs[classInteresting]))
+}
+
+// followerModel is the relevance model of a user following the feed being
+// scraped.
+type followerModel struct {
+	userID uuid.UUID
+	model  *relevanceModel
+}
+
+// loadFeedModels returns the relevance models of the users following the
+// feed.
+func (s *state) loadFeedModels(feedID uuid.UUID) ([]followerModel, error) {
+	rows, err := s.db.GetRelevanceModelsForFeed(context.Background(), feedID)
+	if err != nil {
+		return nil, err
+	}
+
+	followers := []followerModel{}
+	for _, row := range rows {
+		model := newRelevanceModel()
+		if err := json.Unmarshal(row.Model, model); err != nil {
+			fmt.Printf("Skipping the relevance model of %s: %v\n", row.UserID, err)
+			continue
+		}
+		followers = append(followers, followerModel{userID: row.UserID, model: model})
+	}
+	return followers, nil
+}
+
+// scorePost stores the relevance of a post for every follower with a
+// trained model.
+func (s *state) scorePost(followers []followerModel, postID uuid.UUID, tokens []string) error {
+	for _, follower := range followers {
+		err := s.db.SetPostRelevance(context.Background(),
+			database.SetPostRelevanceParams{
+				UserID:    follower.userID,
+				PostID:    postID,
+				Relevance: sql.NullFloat64{Float64: follower.model.score(tokens), Valid: true},
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handlerDismiss(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 {
+		return errors.New("the command dismiss expect one argument")
+	}
+
+	post, err := s.getPost(user, cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.DismissPost(context.Background(),
+		database.DismissPostParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the post could not be dismissed: %v", err)
+	}
+
+	fmt.Printf("Dismissed: %s\n", stripControl(post.Title))
+	return nil
+}
+
+// handlerTrain retrains the relevance model of the user from the posts they
+// starred, opened or dismissed and rescores every post of the followed feeds.
+// Posts marked read in bulk, by a rule or by a cluster are not a sign of
+// interest, so only posts opened or read one by one count, and posts muted
+// by a rule are left out as well.
+func handlerTrain(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 1 {
+		return errors.New("the command train expect no argument")
+	}
+
+	examples, err := s.db.GetTrainingPostsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("the training posts could not be loaded: %v", err)
+	}
+
+	model := newRelevanceModel()
+	for _, example := range examples {
+		tokens := postTokens(example.Title, example.Description, example.FeedUrl)
+		switch {
+		case example.Dismissed:
+			model.train(tokens, classUninteresting, 1)
+		case example.Starred:
+			model.train(tokens, classInteresting, starredWeight)
+		case example.Opened:
+			model.train(tokens, classInteresting, 1)
+		}
+	}
+
+	if !model.trained() {
+		return errors.New("not enough feedback to train: star or open some posts and dismiss others first")
+	}
+
+	data, err := json.Marshal(model)
+	if err != nil {
+		return fmt.Errorf("the relevance model could not be encoded: %v", err)
+	}
+
+	err = s.db.UpsertRelevanceModel(context.Background(),
+		database.UpsertRelevanceModelParams{
+			UserID: user.ID,
+			Model:  data,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the relevance model could not be saved: %v", err)
+	}
+
+	posts, err := s.db.GetPostsToFilterForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("the posts could not be loaded: %v", err)
+	}
+
+	follower := []followerModel{{userID: user.ID, model: model}}
+	for _, post := range posts {
+		if err := s.scorePost(follower, post.ID, postTokens(post.Title, post.Description, post.FeedUrl)); err != nil {
+			return fmt.Errorf("the posts could not be scored: %v", err)
+		}
+	}
+
+	fmt.Printf("Trained on %d posts, %d posts scored\n", len(examples), len(posts))
+	return nil
+}
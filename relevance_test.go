@@ -0,0 +1,40 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := tokenize(`<p>The <a href="https://go.dev">Go</a> compiler &amp; the Linker, 2025!</p>`)
+	want := []string{"compiler", "linker", "2025"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestRelevanceModel(t *testing.T) {
+	model := newRelevanceModel()
+	if model.trained() {
+		t.Fatalf("an empty model should not be trained")
+	}
+
+	model.train(postTokens("Generics in Go", "type parameters and constraints", "https://go.dev/blog"), classInteresting, 1)
+	model.train(postTokens("Go compiler internals", "escape analysis and inlining", "https://go.dev/blog"), classInteresting, 1)
+	model.train(postTokens("Celebrity gossip", "red carpet fashion", "https://gossip.example/rss"), classUninteresting, 1)
+	model.train(postTokens("Sponsored: best mattress", "sponsored deals and fashion", "https://gossip.example/rss"), classUninteresting, 1)
+
+	if !model.trained() {
+		t.Fatalf("the model should be trained")
+	}
+
+	interesting := model.score(postTokens("Go generics constraints", "inlining in the compiler", "https://go.dev/blog"))
+	uninteresting := model.score(postTokens("Fashion week gossip", "sponsored red carpet", "https://gossip.example/rss"))
+
+	if interesting <= 0.5 {
+		t.Errorf("score of an interesting post = %f, want > 0.5", interesting)
+	}
+	if uninteresting >= 0.5 {
+		t.Errorf("score of an uninteresting post = %f, want < 0.5", uninteresting)
+	}
+}
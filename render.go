@@ -0,0 +1,405 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/term"
+)
+
+const (
+	defaultTerminalWidth = 80
+	minRenderWidth       = 20
+)
+
+// linkStyle selects how the links of a description are rendered.
+type linkStyle string
+
+const (
+	// linksFootnotes numbers the links and lists their URLs after the text.
+	linksFootnotes linkStyle = "footnotes"
+	// linksOSC8 turns the link text into a terminal hyperlink.
+	linksOSC8 linkStyle = "osc8"
+)
+
+// renderOptions control the conversion of HTML descriptions to text.
+type renderOptions struct {
+	width int
+	links linkStyle
+}
+
+func parseLinkStyle(value string) (linkStyle, error) {
+	switch style := linkStyle(value); style {
+	case linksFootnotes, linksOSC8:
+		return style, nil
+	default:
+		return linksFootnotes, fmt.Errorf("unknown link style %s, use footnotes or osc8", value)
+	}
+}
+
+// terminalWidth returns the width of the terminal gator writes to, falling
+// back on $COLUMNS and then on 80 columns when it is not a terminal.
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultTerminalWidth
+}
+
+// renderedWord is a word of a paragraph. Links are kept apart from the text
+// so that hyperlink escape sequences do not count in the line width.
+type renderedWord struct {
+	text  string
+	link  string
+	glued bool
+	br    bool
+}
+
+// listState is an open <ul> or <ol> element.
+type listState struct {
+	ordered bool
+	count   int
+}
+
+// htmlRenderer converts an HTML fragment to wrapped text, one block element
+// at a time.
+type htmlRenderer struct {
+	opts renderOptions
+
+	lines      []string
+	words      []renderedWord
+	space      bool
+	link       string
+	itemPrefix string
+	lastItem   bool
+
+	lists  []listState
+	quotes int
+	links  []string
+}
+
+// renderHTML converts an HTML description to text wrapped at the width of
+// the options: paragraphs are separated by blank lines, list items get
+// bullets or numbers, images become placeholders and links are rendered as
+// footnotes or OSC 8 hyperlinks.
+func renderHTML(text string, opts renderOptions) []string {
+	opts.width = max(opts.width, minRenderWidth)
+
+	nodes, err := html.ParseFragment(strings.NewReader(text), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return wrapText(stripHTML(text), opts.width)
+	}
+
+	r := &htmlRenderer{opts: opts}
+	for _, node := range nodes {
+		r.walk(node)
+	}
+	r.flush()
+
+	if len(r.links) > 0 {
+		r.lines = append(r.lines, "")
+		for i, link := range r.links {
+			r.lines = append(r.lines, fmt.Sprintf("[%d] %s", i+1, link))
+		}
+	}
+	return r.lines
+}
+
+func (r *htmlRenderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		r.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Noscript, atom.Iframe, atom.Template:
+	case atom.Br:
+		r.words = append(r.words, renderedWord{br: true})
+		r.space = false
+	case atom.Img:
+		r.image(n)
+	case atom.A:
+		r.anchor(n)
+	case atom.Ul, atom.Ol:
+		r.flush()
+		r.lists = append(r.lists, listState{ordered: n.DataAtom == atom.Ol})
+		r.children(n)
+		r.flush()
+		r.lists = r.lists[:len(r.lists)-1]
+		// A following list starts a new block, nested lists belong to the item.
+		r.lastItem = r.lastItem && len(r.lists) > 0
+	case atom.Li:
+		r.flush()
+		r.itemPrefix = r.bullet()
+		r.children(n)
+		r.flush()
+	case atom.Blockquote:
+		r.flush()
+		r.quotes++
+		r.children(n)
+		r.flush()
+		r.quotes--
+	case atom.Pre:
+		r.flush()
+		r.preformatted(n)
+	case atom.Hr:
+		r.flush()
+		r.block([]string{strings.Repeat("─", min(r.opts.width, 40))}, false)
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Table, atom.Tr, atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Dd:
+		r.flush()
+		r.children(n)
+		r.flush()
+	case atom.Td, atom.Th:
+		r.space = true
+		r.children(n)
+		r.space = true
+	default:
+		r.children(n)
+	}
+}
+
+func (r *htmlRenderer) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		r.walk(child)
+	}
+}
+
+// text adds the words of a text node to the current paragraph. A word not
+// preceded by whitespace sticks to the previous one, as in "<b>go</b>lang".
+func (r *htmlRenderer) text(data string) {
+	if data == "" {
+		return
+	}
+	if startsWithSpace(data) {
+		r.space = true
+	}
+
+	for i, word := range strings.Fields(data) {
+		word = stripControl(word)
+		if word == "" {
+			continue
+		}
+		glued := i == 0 && !r.space && len(r.words) > 0 && !r.words[len(r.words)-1].br
+		r.words = append(r.words, renderedWord{text: word, link: r.link, glued: glued})
+		r.space = false
+	}
+
+	if endsWithSpace(data) {
+		r.space = true
+	}
+}
+
+func (r *htmlRenderer) image(n *html.Node) {
+	if isTrackingPixel(n) {
+		return
+	}
+
+	placeholder := "[image]"
+	if alt := strings.Join(strings.Fields(attr(n, "alt")), " "); alt != "" {
+		placeholder = "[image: " + alt + "]"
+	}
+	r.text(" " + placeholder + " ")
+}
+
+// anchor renders a link. Only web and mailto links are kept, and never with
+// control characters that could end an OSC 8 sequence early.
+func (r *htmlRenderer) anchor(n *html.Node) {
+	raw := strings.TrimSpace(attr(n, "href"))
+	href, ok := sanitizeURL(raw, nil, true)
+	if !ok || href == "" || strings.HasPrefix(href, "#") || strings.IndexFunc(raw, unicode.IsControl) >= 0 {
+		r.children(n)
+		return
+	}
+
+	start := len(r.words)
+	if r.opts.links == linksOSC8 {
+		r.link = href
+	}
+	r.children(n)
+	r.link = ""
+
+	if r.opts.links == linksOSC8 {
+		return
+	}
+
+	// Bare links already show their URL.
+	if len(r.words) == start+1 && r.words[start].text == href {
+		return
+	}
+	if len(r.words) == start {
+		r.text(" " + href + " ")
+		return
+	}
+
+	number := 0
+	for i, link := range r.links {
+		if link == href {
+			number = i + 1
+		}
+	}
+	if number == 0 {
+		r.links = append(r.links, href)
+		number = len(r.links)
+	}
+	r.words = append(r.words, renderedWord{text: fmt.Sprintf("[%d]", number), glued: true})
+}
+
+func (r *htmlRenderer) preformatted(n *html.Node) {
+	var text strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			text.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(n)
+
+	lines := strings.Split(strings.Trim(stripControl(text.String()), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	r.block(lines, false)
+}
+
+// bullet returns the marker of a new item of the innermost list.
+func (r *htmlRenderer) bullet() string {
+	if len(r.lists) == 0 {
+		return "• "
+	}
+
+	list := &r.lists[len(r.lists)-1]
+	list.count++
+	indent := strings.Repeat("  ", len(r.lists)-1)
+	if list.ordered {
+		return fmt.Sprintf("%s%d. ", indent, list.count)
+	}
+	return indent + "• "
+}
+
+// flush wraps the current paragraph and adds it to the output.
+func (r *htmlRenderer) flush() {
+	item := r.itemPrefix != ""
+	prefix, indent := r.itemPrefix, strings.Repeat(" ", utf8.RuneCountInString(r.itemPrefix))
+	r.itemPrefix = ""
+	r.space = false
+
+	words := r.words
+	r.words = nil
+	for len(words) > 0 && words[0].br {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return
+	}
+
+	quote := strings.Repeat("> ", r.quotes)
+	width := r.opts.width - utf8.RuneCountInString(quote+prefix)
+
+	lines := []string{}
+	for i, line := range wrapWords(words, width) {
+		if i == 0 {
+			lines = append(lines, quote+prefix+line)
+		} else {
+			lines = append(lines, quote+indent+line)
+		}
+	}
+	r.block(lines, item)
+}
+
+// block adds lines separated from the previous block by a blank line, except
+// between items of the same list.
+func (r *htmlRenderer) block(lines []string, item bool) {
+	if len(r.lines) > 0 && !(item && r.lastItem) {
+		r.lines = append(r.lines, "")
+	}
+	r.lines = append(r.lines, lines...)
+	r.lastItem = item
+}
+
+// wrapWords lays out the words in lines of at most width visible characters.
+func wrapWords(words []renderedWord, width int) []string {
+	lines := []string{}
+	var line strings.Builder
+	length := 0
+
+	for _, word := range words {
+		if word.br {
+			lines = append(lines, line.String())
+			line.Reset()
+			length = 0
+			continue
+		}
+
+		wordLength := utf8.RuneCountInString(word.text)
+		switch {
+		case length == 0:
+		case word.glued:
+		case length+1+wordLength <= width:
+			line.WriteString(" ")
+			length++
+		default:
+			lines = append(lines, line.String())
+			line.Reset()
+			length = 0
+		}
+
+		line.WriteString(renderWord(word))
+		length += wordLength
+	}
+
+	if length > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// renderWord returns the text of a word, as an OSC 8 hyperlink when it is
+// part of a link.
+func renderWord(word renderedWord) string {
+	if word.link == "" {
+		return word.text
+	}
+	return "\033]8;;" + word.link + "\033\\" + word.text + "\033]8;;\033\\"
+}
+
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func startsWithSpace(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return strings.ContainsRune(" \t\n\r\f", r)
+}
+
+func endsWithSpace(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return strings.ContainsRune(" \t\n\r\f", r)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		links linkStyle
+		want  []string
+	}{
+		{
+			name:  "Plain text",
+			input: "Just some text",
+			want:  []string{"Just some text"},
+		},
+		{
+			name:  "Paragraphs",
+			input: "<p>First paragraph.</p><p>Second &amp; last.</p>",
+			want:  []string{"First paragraph.", "", "Second & last."},
+		},
+		{
+			name:  "Wrapping",
+			input: "<p>the quick brown fox jumps over the lazy dog and keeps running</p>",
+			want:  []string{"the quick brown fox jumps", "over the lazy dog and keeps", "running"},
+		},
+		{
+			name:  "Inline markup",
+			input: "<p>Learn <b>Go</b>lang <i>today</i>.</p>",
+			want:  []string{"Learn Golang today."},
+		},
+		{
+			name:  "Lists",
+			input: "<p>Steps:</p><ol><li>Install</li><li>Run<ul><li>fast</li></ul></li></ol><ul><li>One</li></ul>",
+			want:  []string{"Steps:", "", "1. Install", "2. Run", "  • fast", "", "• One"},
+		},
+		{
+			name:  "Footnotes",
+			input: `<p>Read <a href="https://go.dev">the docs</a> and <a href="https://go.dev">more</a> at <a href="https://pkg.go.dev">https://pkg.go.dev</a>.</p>`,
+			want:  []string{"Read the docs[1] and more[1]", "at https://pkg.go.dev.", "", "[1] https://go.dev"},
+		},
+		{
+			name:  "OSC 8",
+			input: `<a href="https://go.dev">Go</a>`,
+			links: linksOSC8,
+			want:  []string{"\033]8;;https://go.dev\033\\Go\033]8;;\033\\"},
+		},
+		{
+			name:  "Images",
+			input: `<p><img src="a.png" alt="A chart"> text<img src="pixel.gif" width="1" height="1"></p>`,
+			want:  []string{"[image: A chart] text"},
+		},
+		{
+			name:  "Scripts and line breaks",
+			input: "<script>alert(1)</script>line one<br>line two",
+			want:  []string{"line one", "line two"},
+		},
+		{
+			name:  "Quote",
+			input: "<blockquote>Quoted words</blockquote>",
+			want:  []string{"> Quoted words"},
+		},
+		{
+			name:  "Unsafe links",
+			input: `<a href="javascript:alert(1)">a</a> <a href="data:text/html,x">b</a> <a href="https://go.dev/` + "\u009d" + `x">c</a>`,
+			links: linksOSC8,
+			want:  []string{"a b c"},
+		},
+		{
+			name:  "Escape sequences",
+			input: "<p>bad\033]8;;https://evil\033\\text\u009b2J</p><pre>code\033[31m\tred</pre>",
+			want:  []string{"bad]8;;https://evil\\text2J", "", "    code[31m\tred"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			links := c.links
+			if links == "" {
+				links = linksFootnotes
+			}
+			got := renderHTML(c.input, renderOptions{width: 28, links: links})
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("renderHTML(%q) =\n%q\nwant\n%q", c.input, got, c.want)
+			}
+		})
+	}
+}
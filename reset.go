@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const resetUsage = "reset [--posts | --feeds | --user <name>] [--backup <file>] [--yes]"
+
+// resetCount is a line of the summary printed before a reset.
+type resetCount struct {
+	label string
+	count int64
+}
+
+// resetPlan describes what a reset deletes: the summary shown for
+// confirmation, the snapshot written to the backup file and the deletion.
+// When the deletion removes the user named by logout and they are logged
+// in, they are logged out once it is committed. A plan that deletes another
+// user than the logged in one sets confirm to ask for their name.
+type resetPlan struct {
+	question string
+	counts   []resetCount
+	confirm  func(question string, yes bool) error
+	backup   func(q *database.Queries) (string, error)
+	reset    func(tx *state) error
+	logout   string
+}
+
+func handlerReset(s *state, cmd command, current database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	posts := fs.Bool("posts", false, "delete only the posts")
+	feeds := fs.Bool("feeds", false, "delete the feeds with their follows and posts")
+	userName := fs.String("user", "", "delete only a user with their data")
+	backup := fs.String("backup", "", "write the deleted data to a JSON file first")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	scopes := 0
+	for _, set := range []bool{*posts, *feeds, *userName != ""} {
+		if set {
+			scopes++
+		}
+	}
+	if len(args) != 0 || scopes > 1 {
+		return fmt.Errorf("usage: %s", resetUsage)
+	}
+
+	var plan resetPlan
+	switch {
+	case *userName != "":
+		plan, err = s.userResetPlan(*userName, current)
+	case *posts:
+		plan, err = s.postsResetPlan()
+	case *feeds:
+		plan, err = s.feedsResetPlan()
+	default:
+		plan, err = s.fullResetPlan()
+	}
+	if err != nil {
+		return err
+	}
+
+	if *backup != "" {
+		if err := checkBackupPath(*backup); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("This will delete:")
+	for _, c := range plan.counts {
+		fmt.Printf("%18s: %d\n", c.label, c.count)
+	}
+
+	ask := confirm
+	if plan.confirm != nil {
+		ask = plan.confirm
+	}
+	if err := ask(plan.question, *yes); err != nil {
+		return err
+	}
+
+	// The backup is taken in the transaction of the deletion, which sees a
+	// single snapshot, so that it holds exactly the data deleted, including
+	// the posts that go away with the feeds of a deleted user.
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead}
+	err = s.withTxOptions(opts, func(tx *state) error {
+		if *backup != "" {
+			data, err := plan.backup(tx.db)
+			if err != nil {
+				return fmt.Errorf("the backup could not be made: %v", err)
+			}
+			if err := writeBackup(*backup, data); err != nil {
+				return err
+			}
+			fmt.Printf("Backup written to %s\n", *backup)
+		}
+		return plan.reset(tx)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if plan.logout != "" {
+		return s.logoutDeleted(plan.logout)
+	}
+	return nil
+}
+
+func (s *state) fullResetPlan() (resetPlan, error) {
+	counts, err := s.db.CountAllData(context.Background())
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("the data could not be counted: %v", err)
+	}
+
+	return resetPlan{
+		question: "Delete all users, feeds and posts?",
+		counts: []resetCount{
+			{"Users", counts.Users},
+			{"Feeds", counts.Feeds},
+			{"Follows", counts.FeedFollows},
+			{"Posts", counts.Posts},
+			{"Post states", counts.PostStates},
+			{"Story clusters", counts.Clusters},
+			{"Tags", counts.Tags},
+			{"Rules", counts.Rules},
+		},
+		backup: func(q *database.Queries) (string, error) {
+			return q.BackupAllData(context.Background())
+		},
+		reset: func(tx *state) error {
+			if err := deleteAllPosts(tx); err != nil {
+				return err
+			}
+			if _, err := tx.db.DeleteAllFeeds(context.Background()); err != nil {
+				return fmt.Errorf("the feeds could not be deleted: %v", err)
+			}
+			if err := tx.db.Reset(context.Background()); err != nil {
+				return fmt.Errorf("the users could not be deleted: %v", err)
+			}
+
+			fmt.Println("All users, feeds and posts have been deleted")
+			return nil
+		},
+		logout: s.cfg.CurrentUserName,
+	}, nil
+}
+
+func (s *state) postsResetPlan() (resetPlan, error) {
+	counts, err := s.db.CountAllData(context.Background())
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("the data could not be counted: %v", err)
+	}
+
+	return resetPlan{
+		question: "Delete all posts, including starred posts?",
+		counts: []resetCount{
+			{"Posts", counts.Posts},
+			{"Post states", counts.PostStates},
+			{"Story clusters", counts.Clusters},
+		},
+		backup: func(q *database.Queries) (string, error) {
+			return q.BackupAllData(context.Background())
+		},
+		reset: func(tx *state) error {
+			if err := deleteAllPosts(tx); err != nil {
+				return err
+			}
+			fmt.Println("All posts have been deleted, agg will fetch them again")
+			return nil
+		},
+	}, nil
+}
+
+func (s *state) feedsResetPlan() (resetPlan, error) {
+	counts, err := s.db.CountAllData(context.Background())
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("the data could not be counted: %v", err)
+	}
+
+	return resetPlan{
+		question: "Delete all feeds with their follows and posts?",
+		counts: []resetCount{
+			{"Feeds", counts.Feeds},
+			{"Follows", counts.FeedFollows},
+			{"Posts", counts.Posts},
+			{"Post states", counts.PostStates},
+			{"Story clusters", counts.Clusters},
+		},
+		backup: func(q *database.Queries) (string, error) {
+			return q.BackupAllData(context.Background())
+		},
+		reset: func(tx *state) error {
+			if err := deleteAllPosts(tx); err != nil {
+				return err
+			}
+			if _, err := tx.db.DeleteAllFeeds(context.Background()); err != nil {
+				return fmt.Errorf("the feeds could not be deleted: %v", err)
+			}
+			fmt.Println("All feeds have been deleted")
+			return nil
+		},
+	}, nil
+}
+
+func (s *state) userResetPlan(name string, current database.User) (resetPlan, error) {
+	user, err := s.getUser(name)
+	if err != nil {
+		return resetPlan{}, err
+	}
+
+	counts, err := s.db.CountUserData(context.Background(), user.ID)
+	if err != nil {
+		return resetPlan{}, fmt.Errorf("the data of %s could not be counted: %v", user.Name, err)
+	}
+
+	return resetPlan{
+		question: fmt.Sprintf("Delete the user %s with their data?", user.Name),
+		counts: []resetCount{
+			{"Users", 1},
+			{"Follows", counts.FeedFollows},
+			{"Post states", counts.PostStates},
+			{"Tags", counts.Tags},
+			{"Rules", counts.Rules},
+			{"Feeds", counts.DeletedFeeds},
+			{"Transferred feeds", counts.TransferredFeeds},
+		},
+		confirm: func(question string, yes bool) error {
+			return confirmUserAction(question, user, current, yes)
+		},
+		backup: func(q *database.Queries) (string, error) {
+			return q.BackupUserData(context.Background(), user.ID)
+		},
+		reset: func(tx *state) error {
+			return tx.deleteUser(user)
+		},
+		logout: user.Name,
+	}, nil
+}
+
+// deleteAllPosts deletes the posts, starred or not, and their clusters.
+func deleteAllPosts(tx *state) error {
+	if _, err := tx.db.DeleteAllPosts(context.Background()); err != nil {
+		return fmt.Errorf("the posts could not be deleted: %v", err)
+	}
+	if _, err := tx.db.DeleteAllClusters(context.Background()); err != nil {
+		return fmt.Errorf("the story clusters could not be deleted: %v", err)
+	}
+	return nil
+}
+
+// checkBackupPath fails when the backup file already exists, so that the
+// reset is refused before asking for confirmation.
+func checkBackupPath(path string) error {
+	_, err := os.Lstat(path)
+	if err == nil {
+		return fmt.Errorf("the backup file %s already exists", path)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("the backup file could not be checked: %v", err)
+	}
+	return nil
+}
+
+// writeBackup writes the snapshot taken before a reset. An existing file is
+// never overwritten.
+func writeBackup(path, data string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("the backup file %s already exists", path)
+	}
+	if err != nil {
+		return fmt.Errorf("the backup file could not be created: %v", err)
+	}
+
+	if _, err := file.WriteString(data + "\n"); err != nil {
+		file.Close()
+		return fmt.Errorf("the backup could not be written: %v", err)
+	}
+	return file.Close()
+}
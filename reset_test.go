@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup.json")
+
+	if err := writeBackup(path, `{"users": []}`); err != nil {
+		t.Fatalf("writeBackup() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("the backup could not be read: %v", err)
+	}
+	if got, want := string(data), "{\"users\": []}\n"; got != want {
+		t.Errorf("backup = %q, want %q", got, want)
+	}
+
+	if err := writeBackup(path, `{}`); err == nil {
+		t.Errorf("writeBackup() overwrote an existing file")
+	}
+
+	data, _ = os.ReadFile(path)
+	if got, want := string(data), "{\"users\": []}\n"; got != want {
+		t.Errorf("backup after second write = %q, want %q", got, want)
+	}
+}
+
+func TestCheckBackupPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "backup.json")
+
+	if err := checkBackupPath(path); err != nil {
+		t.Errorf("checkBackupPath() on a new file error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkBackupPath(path); err == nil {
+		t.Errorf("checkBackupPath() accepted an existing file")
+	}
+}
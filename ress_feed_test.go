@@ -99,3 +99,27 @@ func TestFetchFeed(t *testing.T) {
 // Note: You need to have the actual `fetchFeed` function defined or imported
 // in the same package for this test to run. You also need the definition
 // of the `RSSFeed` struct.
+
+func TestParsePubDate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pubDate string
+		wantOK  bool
+	}{
+		{name: "RFC1123Z", pubDate: "Mon, 02 Jan 2006 15:04:05 -0700", wantOK: true},
+		{name: "RFC1123", pubDate: "Mon, 02 Jan 2006 15:04:05 MST", wantOK: true},
+		{name: "Single digit day", pubDate: "Mon, 2 Jan 2006 15:04:05 -0700", wantOK: true},
+		{name: "RFC3339", pubDate: "2006-01-02T15:04:05Z", wantOK: true},
+		{name: "Empty", pubDate: "", wantOK: false},
+		{name: "Garbage", pubDate: "yesterday", wantOK: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, ok := parsePubDate(tc.pubDate)
+			if ok != tc.wantOK {
+				t.Errorf("parsePubDate(%q) ok = %v, want %v", tc.pubDate, ok, tc.wantOK)
+			}
+		})
+	}
+}
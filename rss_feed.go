@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"html"
 	"io"
@@ -10,6 +11,33 @@ import (
 	"time"
 )
 
+var (
+	// errFeedUnreachable is wrapped by fetchFeed when the server cannot be
+	// reached or does not answer 200 OK.
+	errFeedUnreachable = errors.New("the feed is unreachable")
+	// errNotAFeed is wrapped by fetchFeed when the answer is not RSS.
+	errNotAFeed = errors.New("the answer is not an RSS feed")
+)
+
+// feedError is a failure of fetchFeed of the kind errFeedUnreachable or
+// errNotAFeed.
+type feedError struct {
+	kind  error
+	cause error
+}
+
+func (e *feedError) Error() string {
+	return fmt.Sprintf("%v: %v", e.kind, e.cause)
+}
+
+func (e *feedError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *feedError) Unwrap() error {
+	return e.cause
+}
+
 type RSSFeed struct {
 	Channel struct {
 		Title       string    `xml:"title"`
@@ -20,10 +48,22 @@ type RSSFeed struct {
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string `xml:"category"`
+}
+
+// AuthorName returns the author of the item, falling back to the Dublin
+// Core creator used by many feeds instead of <author>.
+func (item RSSItem) AuthorName() string {
+	if item.Author != "" {
+		return item.Author
+	}
+	return item.Creator
 }
 
 func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
@@ -42,11 +82,14 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	res, err := client.Do(req)
 
 	if err != nil {
-		fmt.Printf("the request failed for %s with the error: %s", feedURL, err)
-		return nil, err
+		return nil, &feedError{kind: errFeedUnreachable, cause: err}
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode != http.StatusOK {
+		return nil, &feedError{kind: errFeedUnreachable, cause: fmt.Errorf("the server answered %s", res.Status)}
+	}
+
 	feed := RSSFeed{}
 
 	data, err := io.ReadAll(res.Body)
@@ -56,8 +99,7 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	}
 
 	if err := xml.Unmarshal(data, &feed); err != nil {
-		fmt.Printf("the xml file wasnt decoded properly: %s", err)
-		return nil, err
+		return nil, &feedError{kind: errNotAFeed, cause: err}
 	}
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
@@ -71,3 +113,24 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	return &feed, nil
 
 }
+
+// parsePubDate parses the pubDate of an item. RSS uses RFC 822 dates but
+// many feeds publish RFC 3339 ones, so every known layout is tried.
+func parsePubDate(pubDate string) (time.Time, bool) {
+	layouts := []string{
+		time.RFC1123Z,
+		time.RFC1123,
+		time.RFC822Z,
+		time.RFC822,
+		time.RFC3339,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, pubDate); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
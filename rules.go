@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const ruleUsage = "usage: rule list | rule add <mute|highlight|mark-read> <any|title|description|author|category|feed> <pattern> [--regex] | rule delete <id>"
+
+var (
+	ruleActionNames = []string{"mute", "highlight", "mark-read"}
+	ruleFieldNames  = []string{"any", "title", "description", "author", "category", "feed"}
+)
+
+// categorySeparator joins the categories of a post in the categories
+// column.
+const categorySeparator = ", "
+
+// ruleTarget holds the parts of a post a rule can match.
+type ruleTarget struct {
+	title       string
+	description string
+	author      string
+	categories  []string
+	feedName    string
+	feedURL     string
+}
+
+// ruleMatcher is a rule with its pattern compiled. Keywords are matched
+// case-insensitively anywhere in the field.
+type ruleMatcher struct {
+	rule    database.Rule
+	pattern *regexp.Regexp
+}
+
+func compileRule(rule database.Rule) (ruleMatcher, error) {
+	if !slices.Contains(ruleFieldNames, rule.Field) {
+		return ruleMatcher{}, fmt.Errorf("unknown rule field %q", rule.Field)
+	}
+
+	if !slices.Contains(ruleActionNames, rule.Action) {
+		return ruleMatcher{}, fmt.Errorf("unknown rule action %q", rule.Action)
+	}
+
+	expr := rule.Pattern
+	switch rule.MatchType {
+	case "keyword":
+		expr = "(?i)" + regexp.QuoteMeta(rule.Pattern)
+	case "regex":
+	default:
+		return ruleMatcher{}, fmt.Errorf("unknown rule match type %q", rule.MatchType)
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return ruleMatcher{}, fmt.Errorf("invalid regular expression %q: %v", rule.Pattern, err)
+	}
+
+	return ruleMatcher{rule: rule, pattern: pattern}, nil
+}
+
+func (m ruleMatcher) matches(target ruleTarget) bool {
+	switch m.rule.Field {
+	case "title":
+		return m.pattern.MatchString(target.title)
+	case "description":
+		return m.pattern.MatchString(target.description)
+	case "author":
+		return m.pattern.MatchString(target.author)
+	case "category":
+		return slices.ContainsFunc(target.categories, m.pattern.MatchString)
+	case "feed":
+		return m.pattern.MatchString(target.feedName) || m.pattern.MatchString(target.feedURL)
+	}
+
+	fields := append([]string{target.title, target.description, target.author, target.feedName}, target.categories...)
+	return slices.ContainsFunc(fields, m.pattern.MatchString)
+}
+
+// ruleActions are the actions of every rule matching a post.
+type ruleActions struct {
+	mute      bool
+	highlight bool
+	markRead  bool
+}
+
+func (a ruleActions) any() bool {
+	return a.mute || a.highlight || a.markRead
+}
+
+func evaluateRules(matchers []ruleMatcher, target ruleTarget) ruleActions {
+	actions := ruleActions{}
+	for _, m := range matchers {
+		if !m.matches(target) {
+			continue
+		}
+		switch m.rule.Action {
+		case "mute":
+			actions.mute = true
+		case "highlight":
+			actions.highlight = true
+		case "mark-read":
+			actions.markRead = true
+		}
+	}
+	return actions
+}
+
+func splitCategories(categories string) []string {
+	if categories == "" {
+		return nil
+	}
+	return strings.Split(categories, categorySeparator)
+}
+
+// setPostFlags stores the actions of the matching rules in the state of the
+// post for the user.
+func (s *state) setPostFlags(userID, postID uuid.UUID, actions ruleActions) error {
+	return s.db.SetPostFlags(context.Background(),
+		database.SetPostFlagsParams{
+			UserID:    userID,
+			PostID:    postID,
+			MarkRead:  actions.markRead,
+			Mute:      actions.mute,
+			Highlight: actions.highlight,
+		},
+	)
+}
+
+// followerRules are the rules of a user following the feed being scraped.
+type followerRules struct {
+	userID   uuid.UUID
+	feedName string
+	feedURL  string
+	matchers []ruleMatcher
+}
+
+// loadFeedRules returns the rules of every user following the feed.
+func (s *state) loadFeedRules(feedID uuid.UUID) ([]followerRules, error) {
+	rows, err := s.db.GetRulesForFeed(context.Background(), feedID)
+	if err != nil {
+		return nil, err
+	}
+
+	followers := []followerRules{}
+	for _, row := range rows {
+		rule := database.Rule{
+			ID:        row.ID,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			UserID:    row.UserID,
+			Field:     row.Field,
+			MatchType: row.MatchType,
+			Pattern:   row.Pattern,
+			Action:    row.Action,
+		}
+
+		matcher, err := compileRule(rule)
+		if err != nil {
+			fmt.Printf("Skipping rule %s: %v\n", rule.ID, err)
+			continue
+		}
+
+		if len(followers) == 0 || followers[len(followers)-1].userID != row.UserID {
+			followers = append(followers, followerRules{
+				userID:   row.UserID,
+				feedName: row.FeedName,
+				feedURL:  row.FeedUrl,
+			})
+		}
+		last := &followers[len(followers)-1]
+		last.matchers = append(last.matchers, matcher)
+	}
+
+	return followers, nil
+}
+
+// applyFeedRules evaluates the rules of the followers of a feed on a newly
+// ingested post.
+func (s *state) applyFeedRules(followers []followerRules, postID uuid.UUID, target ruleTarget) error {
+	for _, follower := range followers {
+		target.feedName = follower.feedName
+		target.feedURL = follower.feedURL
+
+		actions := evaluateRules(follower.matchers, target)
+		if !actions.any() {
+			continue
+		}
+
+		if err := s.setPostFlags(follower.userID, postID, actions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handlerRule manages the filter rules used to mute, highlight or mark as
+// read the posts of the followed feeds.
+func handlerRule(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New(ruleUsage)
+	}
+
+	switch cmd.args[1] {
+	case "list":
+		if len(cmd.args) != 2 {
+			return errors.New(ruleUsage)
+		}
+		return s.listRules(user)
+	case "add":
+		return s.addRule(user, cmd.args[2:])
+	case "delete":
+		if len(cmd.args) != 3 {
+			return errors.New(ruleUsage)
+		}
+		return s.deleteRule(user, cmd.args[2])
+	}
+
+	return errors.New(ruleUsage)
+}
+
+func (s *state) listRules(user database.User) error {
+	rules, err := s.db.GetRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("the rules could not be loaded: %v", err)
+	}
+
+	if len(rules) == 0 {
+		fmt.Println("There are no rules, add one with: gator rule add <action> <field> <pattern>")
+		return nil
+	}
+
+	for _, rule := range rules {
+		fmt.Printf("%s  %-9s %-11s %-7s %s\n", rule.ID, rule.Action, rule.Field, rule.MatchType, rule.Pattern)
+	}
+	return nil
+}
+
+func (s *state) addRule(user database.User, args []string) error {
+	matchType := "keyword"
+	if i := slices.Index(args, "--regex"); i >= 0 {
+		matchType = "regex"
+		args = slices.Delete(args, i, i+1)
+	}
+
+	if len(args) != 3 {
+		return errors.New(ruleUsage)
+	}
+
+	rule := database.Rule{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Action:    args[0],
+		Field:     args[1],
+		Pattern:   args[2],
+		MatchType: matchType,
+	}
+
+	matcher, err := compileRule(rule)
+	if err != nil {
+		return err
+	}
+
+	rule, err = s.db.CreateRule(context.Background(),
+		database.CreateRuleParams{
+			ID:        rule.ID,
+			UserID:    rule.UserID,
+			Field:     rule.Field,
+			MatchType: rule.MatchType,
+			Pattern:   rule.Pattern,
+			Action:    rule.Action,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the rule could not be created: %v", err)
+	}
+	matcher.rule = rule
+
+	posts, err := s.db.GetPostsToFilterForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("the posts could not be loaded: %v", err)
+	}
+
+	matched := 0
+	for _, post := range posts {
+		actions := evaluateRules([]ruleMatcher{matcher}, ruleTarget{
+			title:       post.Title,
+			description: post.Description,
+			author:      post.Author,
+			categories:  splitCategories(post.Categories),
+			feedName:    post.FeedName,
+			feedURL:     post.FeedUrl,
+		})
+
+		if !actions.any() {
+			continue
+		}
+
+		if err := s.setPostFlags(user.ID, post.ID, actions); err != nil {
+			return fmt.Errorf("the rule could not be applied: %v", err)
+		}
+		matched++
+	}
+
+	fmt.Printf("Rule created: %s (%d existing posts matched)\n", rule.ID, matched)
+	return nil
+}
+
+func (s *state) deleteRule(user database.User, ref string) error {
+	ruleID, err := uuid.Parse(ref)
+	if err != nil {
+		return fmt.Errorf("invalid rule ID %s: %v", ref, err)
+	}
+
+	count, err := s.db.DeleteRule(context.Background(),
+		database.DeleteRuleParams{
+			ID:     ruleID,
+			UserID: user.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the rule could not be deleted: %v", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("the rule %s does not exist", ref)
+	}
+
+	fmt.Printf("Rule deleted: %s\n", ref)
+	return nil
+}
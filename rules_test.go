@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+func TestEvaluateRules(t *testing.T) {
+	target := ruleTarget{
+		title:       "Weekly Roundup #42: Go 1.24 released",
+		description: "<p>Sponsored by ACME</p>",
+		author:      "jane@example.com (Jane Doe)",
+		categories:  []string{"golang", "releases"},
+		feedName:    "Go Weekly",
+		feedURL:     "https://golangweekly.com/rss",
+	}
+
+	testCases := []struct {
+		name string
+		rule database.Rule
+		want ruleActions
+	}{
+		{
+			name: "Keyword is case insensitive",
+			rule: database.Rule{Field: "title", MatchType: "keyword", Pattern: "weekly roundup", Action: "mute"},
+			want: ruleActions{mute: true},
+		},
+		{
+			name: "Keyword is not a regex",
+			rule: database.Rule{Field: "title", MatchType: "keyword", Pattern: "1.2.", Action: "mute"},
+			want: ruleActions{},
+		},
+		{
+			name: "Regex on description",
+			rule: database.Rule{Field: "description", MatchType: "regex", Pattern: `(?i)sponsored\s+by`, Action: "mark-read"},
+			want: ruleActions{markRead: true},
+		},
+		{
+			name: "Category",
+			rule: database.Rule{Field: "category", MatchType: "regex", Pattern: `^golang$`, Action: "highlight"},
+			want: ruleActions{highlight: true},
+		},
+		{
+			name: "Feed URL",
+			rule: database.Rule{Field: "feed", MatchType: "keyword", Pattern: "golangweekly.com", Action: "highlight"},
+			want: ruleActions{highlight: true},
+		},
+		{
+			name: "Author does not match",
+			rule: database.Rule{Field: "author", MatchType: "keyword", Pattern: "john", Action: "mute"},
+			want: ruleActions{},
+		},
+		{
+			name: "Any field",
+			rule: database.Rule{Field: "any", MatchType: "keyword", Pattern: "acme", Action: "mute"},
+			want: ruleActions{mute: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matcher, err := compileRule(tc.rule)
+			if err != nil {
+				t.Fatalf("compileRule() error = %v", err)
+			}
+
+			got := evaluateRules([]ruleMatcher{matcher}, target)
+			if got != tc.want {
+				t.Errorf("evaluateRules() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompileRuleErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule database.Rule
+	}{
+		{name: "Invalid regex", rule: database.Rule{Field: "title", MatchType: "regex", Pattern: "(", Action: "mute"}},
+		{name: "Unknown field", rule: database.Rule{Field: "body", MatchType: "keyword", Pattern: "x", Action: "mute"}},
+		{name: "Unknown action", rule: database.Rule{Field: "title", MatchType: "keyword", Pattern: "x", Action: "delete"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := compileRule(tc.rule); err == nil {
+				t.Errorf("compileRule() expected an error")
+			}
+		})
+	}
+}
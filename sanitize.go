@@ -0,0 +1,218 @@
+package main
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedAttributes lists the elements kept by sanitizeHTML with the
+// attributes they may keep. Elements not listed are replaced by their
+// content.
+var allowedAttributes = map[atom.Atom][]string{
+	atom.A:          {"href", "title"},
+	atom.Abbr:       {"title"},
+	atom.B:          nil,
+	atom.Blockquote: {"cite"},
+	atom.Br:         nil,
+	atom.Caption:    nil,
+	atom.Cite:       nil,
+	atom.Code:       nil,
+	atom.Dd:         nil,
+	atom.Del:        nil,
+	atom.Div:        nil,
+	atom.Dl:         nil,
+	atom.Dt:         nil,
+	atom.Em:         nil,
+	atom.Figcaption: nil,
+	atom.Figure:     nil,
+	atom.H1:         nil,
+	atom.H2:         nil,
+	atom.H3:         nil,
+	atom.H4:         nil,
+	atom.H5:         nil,
+	atom.H6:         nil,
+	atom.Hr:         nil,
+	atom.I:          nil,
+	atom.Img:        {"src", "alt", "title", "width", "height"},
+	atom.Ins:        nil,
+	atom.Li:         nil,
+	atom.Ol:         nil,
+	atom.P:          nil,
+	atom.Pre:        nil,
+	atom.Q:          {"cite"},
+	atom.S:          nil,
+	atom.Small:      nil,
+	atom.Span:       nil,
+	atom.Strong:     nil,
+	atom.Sub:        nil,
+	atom.Sup:        nil,
+	atom.Table:      nil,
+	atom.Tbody:      nil,
+	atom.Td:         {"colspan", "rowspan"},
+	atom.Tfoot:      nil,
+	atom.Th:         {"colspan", "rowspan"},
+	atom.Thead:      nil,
+	atom.Tr:         nil,
+	atom.U:          nil,
+	atom.Ul:         nil,
+}
+
+// droppedElements are removed together with their content.
+var droppedElements = map[atom.Atom]bool{
+	atom.Applet:   true,
+	atom.Base:     true,
+	atom.Button:   true,
+	atom.Embed:    true,
+	atom.Form:     true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Head:     true,
+	atom.Iframe:   true,
+	atom.Input:    true,
+	atom.Link:     true,
+	atom.Math:     true,
+	atom.Meta:     true,
+	atom.Noscript: true,
+	atom.Object:   true,
+	atom.Script:   true,
+	atom.Select:   true,
+	atom.Style:    true,
+	atom.Svg:      true,
+	atom.Template: true,
+	atom.Textarea: true,
+	atom.Title:    true,
+}
+
+// sanitizeHTML keeps only the allowlisted elements and attributes of an
+// HTML description. Scripts, styles, frames, forms, event handlers and
+// tracking pixels are removed, and relative links and images are resolved
+// against the link of the item.
+func sanitizeHTML(text, itemLink string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(text), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return html.EscapeString(stripHTML(text))
+	}
+
+	base, err := url.Parse(itemLink)
+	if err != nil || !base.IsAbs() {
+		base = nil
+	}
+
+	var out strings.Builder
+	for _, node := range nodes {
+		for _, clean := range sanitizeNode(node, base) {
+			html.Render(&out, clean)
+		}
+	}
+	return out.String()
+}
+
+// sanitizeNode returns the nodes replacing n in the sanitized tree: n itself
+// with its allowed attributes, its sanitized children when the element is not
+// allowed or nothing when it is dropped.
+func sanitizeNode(n *html.Node, base *url.URL) []*html.Node {
+	switch n.Type {
+	case html.TextNode:
+		return []*html.Node{{Type: html.TextNode, Data: n.Data}}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	if droppedElements[n.DataAtom] || isTrackingPixel(n) {
+		return nil
+	}
+
+	children := []*html.Node{}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		children = append(children, sanitizeNode(child, base)...)
+	}
+
+	allowed, ok := allowedAttributes[n.DataAtom]
+	if !ok {
+		return children
+	}
+
+	clean := &html.Node{Type: html.ElementNode, Data: n.Data, DataAtom: n.DataAtom}
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !slices.Contains(allowed, a.Key) {
+			continue
+		}
+		if a.Key == "href" || a.Key == "src" || a.Key == "cite" {
+			value, ok := sanitizeURL(a.Val, base, n.DataAtom == atom.A)
+			if !ok {
+				continue
+			}
+			a.Val = value
+		}
+		clean.Attr = append(clean.Attr, html.Attribute{Key: a.Key, Val: a.Val})
+	}
+
+	if n.DataAtom == atom.Img && attr(clean, "src") == "" {
+		return nil
+	}
+	if n.DataAtom == atom.A && attr(clean, "href") != "" {
+		clean.Attr = append(clean.Attr, html.Attribute{Key: "rel", Val: "nofollow noopener noreferrer"})
+	}
+
+	for _, child := range children {
+		clean.AppendChild(child)
+	}
+	return []*html.Node{clean}
+}
+
+// sanitizeURL resolves a URL of an attribute against the item link and
+// accepts only web links, plus mailto for anchors.
+func sanitizeURL(value string, base *url.URL, anchor bool) (string, bool) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "#") {
+		return value, anchor
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.String(), true
+	case "mailto":
+		return u.String(), anchor
+	default:
+		return "", false
+	}
+}
+
+// stripControl removes the control characters of a text, except tabs and
+// new lines, so that feeds cannot send escape sequences to the terminal.
+// Every string of a feed goes through it before being printed.
+func stripControl(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// isTrackingPixel recognizes the invisible images used to track readers.
+func isTrackingPixel(n *html.Node) bool {
+	if n.DataAtom != atom.Img {
+		return false
+	}
+	width, height := attr(n, "width"), attr(n, "height")
+	return width == "0" || width == "1" || height == "0" || height == "1"
+}
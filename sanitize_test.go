@@ -0,0 +1,87 @@
+package main
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	const link = "https://blog.example.com/posts/hello"
+
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "Allowed markup",
+			input: "<p>Hello <b>world</b></p>",
+			want:  "<p>Hello <b>world</b></p>",
+		},
+		{
+			name:  "Scripts and styles",
+			input: "<p>Hi</p><script>alert(1)</script><style>p{}</style>",
+			want:  "<p>Hi</p>",
+		},
+		{
+			name:  "Event handlers",
+			input: `<p onclick="steal()" style="color:red" class="x">Text</p>`,
+			want:  "<p>Text</p>",
+		},
+		{
+			name:  "Unknown elements are unwrapped",
+			input: "<font color=red>Red <marquee>text</marquee></font>",
+			want:  "Red text",
+		},
+		{
+			name:  "Relative links",
+			input: `<a href="../about">About</a> <img src="/img/a.png" alt="A">`,
+			want:  `<a href="https://blog.example.com/about" rel="nofollow noopener noreferrer">About</a> <img src="https://blog.example.com/img/a.png" alt="A"/>`,
+		},
+		{
+			name:  "Dangerous URLs",
+			input: `<a href="javascript:alert(1)">Click</a><img src="data:image/png;base64,AAAA">`,
+			want:  "<a>Click</a>",
+		},
+		{
+			name:  "Tracking pixels",
+			input: `<p>Text<img src="https://t.example.com/p.gif" width="1" height="1"></p>`,
+			want:  "<p>Text</p>",
+		},
+		{
+			name:  "Frames and forms",
+			input: `<iframe src="https://evil.example.com"></iframe><form><input name="q"></form>Kept`,
+			want:  "Kept",
+		},
+		{
+			name:  "Escaped text",
+			input: "Fish &amp; chips &lt;3",
+			want:  "Fish &amp; chips &lt;3",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := sanitizeHTML(c.input, link); got != c.want {
+				t.Errorf("sanitizeHTML(%q) =\n%s\nwant\n%s", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+func TestStripControl(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Plain title", input: "Go 1.24 is out", want: "Go 1.24 is out"},
+		{name: "OSC 8 link", input: "Click\x1b]8;;https://evil.example\x1b\\here", want: "Click]8;;https://evil.example\\here"},
+		{name: "C1 CSI", input: "Title\u009b2J cleared", want: "Title2J cleared"},
+		{name: "DEL and carriage return", input: "a\x7fb\rc", want: "abc"},
+		{name: "Tabs and new lines", input: "a\tb\nc", want: "a\tb\nc"},
+	}
+
+	for _, c := range cases {
+		if got := stripControl(c.input); got != c.want {
+			t.Errorf("%s: stripControl(%q) = %q, want %q", c.name, c.input, got, c.want)
+		}
+	}
+}
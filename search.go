@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const (
+	highlightStart = "\033[1m"
+	highlightStop  = "\033[0m"
+)
+
+// highlight turns the <<<match>>> markers produced by ts_headline into bold
+// text and collapses the whitespace of the snippet into a single line. The
+// entities left by the HTML of descriptions are decoded and the control
+// characters of the feed removed.
+func highlight(text string) string {
+	text = strings.Join(strings.Fields(stripControl(html.UnescapeString(text))), " ")
+	text = strings.ReplaceAll(text, "<<<", highlightStart)
+	return strings.ReplaceAll(text, ">>>", highlightStop)
+}
+
+// handlerSearch runs a full-text search over the posts of the followed
+// feeds. The query accepts the web search syntax of Postgres: "quoted
+// phrases", OR and -excluded words.
+func handlerSearch(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	feedRef := fs.String("feed", "", "only search the posts of this feed (name, ID or URL)")
+	tag := fs.String("tag", "", "only search the posts of feeds with this tag")
+	since := fs.String("since", "", "only posts published after this date (YYYY-MM-DD or an age like 7d)")
+	until := fs.String("until", "", "only posts published up to this date included (YYYY-MM-DD or an age like 7d)")
+	limit := fs.Int("limit", defaultBrowseLimit, "maximum number of results")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return errors.New("the command search expect a query")
+	}
+
+	if *limit < 1 {
+		return fmt.Errorf("the limit must be a positive number: %d", *limit)
+	}
+
+	params := database.SearchPostsForUserParams{
+		Query:  strings.Join(args, " "),
+		UserID: user.ID,
+		Limit:  int32(*limit),
+	}
+
+	if *feedRef != "" {
+		candidates, err := s.followedFeedCandidates(user)
+		if err != nil {
+			return err
+		}
+		feed, err := s.resolveFeed(*feedRef, candidates)
+		if err != nil {
+			return err
+		}
+		params.FeedUrl = sql.NullString{String: feed.Url, Valid: true}
+	}
+
+	if *tag != "" {
+		params.Tag = sql.NullString{String: *tag, Valid: true}
+	}
+
+	if *since != "" {
+		t, err := parseTimeArg(*since)
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+
+	if *until != "" {
+		t, err := parseUntilArg(*until)
+		if err != nil {
+			return err
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+
+	results, err := s.db.SearchPostsForUser(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("the search failed: %v", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No posts match %q\n", params.Query)
+		return nil
+	}
+
+	for _, result := range results {
+		fmt.Printf("         ID: %s\n", shortID(result.ID))
+		fmt.Printf("      Title: %s\n", highlight(result.TitleHighlight))
+		fmt.Printf("       Feed: %s\n", stripControl(result.FeedName))
+		if result.PublishedAt.Valid {
+			fmt.Printf("  Published: %s\n", result.PublishedAt.Time.Format(time.RFC1123))
+		}
+		fmt.Printf("  Relevance: %.3f\n", result.Rank)
+		fmt.Printf("    Snippet: %s\n", highlight(result.Snippet))
+		fmt.Printf("        URL: %s\n", stripControl(result.Url))
+		fmt.Printf("------------------\n\n")
+	}
+
+	return nil
+}
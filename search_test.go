@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Match", input: "learn <<<go>>> today", want: "learn " + highlightStart + "go" + highlightStop + " today"},
+		{name: "Entities and whitespace", input: "Tom &amp; Jerry\n  <<<cartoon>>>", want: "Tom & Jerry " + highlightStart + "cartoon" + highlightStop},
+		{name: "Escape sequences", input: "bad\x1b]8;;https://evil.example\x1b\\ <<<title>>>\u009b2J", want: "bad]8;;https://evil.example\\ " + highlightStart + "title" + highlightStop + "2J"},
+	}
+
+	for _, c := range cases {
+		if got := highlight(c.input); got != c.want {
+			t.Errorf("%s: highlight(%q) = %q, want %q", c.name, c.input, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
+
+-- name: RenameUser :one
+UPDATE users
+SET name = sqlc.arg('new_name'), updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeleteFeedFollowsForUser :execrows
+DELETE FROM feed_follows
+WHERE user_id = $1;
+
+-- name: DeleteTagsForUser :execrows
+DELETE FROM tags
+WHERE user_id = $1;
+
+-- name: DeleteRulesForUser :execrows
+DELETE FROM rules
+WHERE user_id = $1;
+
+-- name: DeletePostStatesForUser :execrows
+DELETE FROM post_states
+WHERE user_id = $1;
+
+-- name: DeleteRelevanceModelForUser :execrows
+DELETE FROM relevance_models
+WHERE user_id = $1;
@@ -0,0 +1,9 @@
+-- name: SetFeedFollowFetchFullContent :execrows
+UPDATE feed_follows
+SET fetch_full_content = $1, updated_at = NOW()
+WHERE user_id = $2 AND feed_id = $3;
+
+-- name: SetPostFullContent :exec
+UPDATE posts
+SET full_content = $2, updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,45 @@
+-- name: CreateCluster :one
+INSERT INTO clusters (id, created_at, updated_at)
+VALUES (
+    $1,
+    NOW(),
+    NOW()
+) RETURNING *;
+
+-- name: SetPostCluster :exec
+UPDATE posts
+SET cluster_id = sqlc.arg('cluster_id'), updated_at = NOW()
+WHERE id = sqlc.arg('post_id');
+
+-- name: TouchCluster :exec
+UPDATE clusters SET updated_at = NOW() WHERE id = $1;
+
+-- cluster_has_feed tells that the cluster of a candidate already holds a
+-- post of the feed of the new post.
+-- name: GetClusterCandidates :many
+SELECT id, simhash, cluster_id, (cluster_id IS NOT NULL AND EXISTS (
+    SELECT 1 FROM posts AS member
+    WHERE member.cluster_id = posts.cluster_id AND member.feed_id = sqlc.arg('feed_id')::uuid
+))::bool AS cluster_has_feed
+FROM posts
+WHERE simhash IS NOT NULL
+    AND created_at >= sqlc.arg('since')
+    AND id <> sqlc.arg('post_id')
+    AND feed_id <> sqlc.arg('feed_id')::uuid;
+
+-- name: GetClusterSourcesForUser :many
+SELECT posts.id, posts.url, COALESCE(feed_follows.title, feeds.name) AS feed_name
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.cluster_id = $1 AND feed_follows.user_id = $2
+ORDER BY posts.published_at ASC NULLS LAST, posts.id;
+
+-- name: MarkClusterRead :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at)
+SELECT feed_follows.user_id, posts.id, NOW(), NOW(), NOW()
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE posts.cluster_id = $1 AND feed_follows.user_id = $2
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = COALESCE(post_states.read_at, NOW()), updated_at = NOW();
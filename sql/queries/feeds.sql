@@ -0,0 +1,29 @@
+-- name: CountOtherFollowers :one
+SELECT COUNT(*) FROM feed_follows
+WHERE feed_id = $1 AND user_id <> $2;
+
+-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: UpdateFeed :one
+UPDATE feeds
+SET name = sqlc.arg('name'), url = sqlc.arg('url'), updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetAllFeeds :many
+SELECT * FROM feeds
+ORDER BY name;
+
+-- name: GetFollowedFeeds :many
+SELECT feeds.*, COALESCE(feed_follows.title, feeds.name)::text AS follow_name
+FROM feeds
+INNER JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY follow_name;
@@ -0,0 +1,14 @@
+-- name: PauseFeedFollow :execrows
+UPDATE feed_follows
+SET paused_at = NOW(), paused_until = sqlc.narg('paused_until'), updated_at = NOW()
+WHERE user_id = sqlc.arg('user_id') AND feed_id = sqlc.arg('feed_id');
+
+-- name: ResumeFeedFollow :execrows
+UPDATE feed_follows
+SET paused_at = NULL, paused_until = NULL, updated_at = NOW()
+WHERE user_id = $1 AND feed_id = $2 AND paused_at IS NOT NULL;
+
+-- name: SetFeedPaused :execrows
+UPDATE feeds
+SET paused_at = CASE WHEN sqlc.arg('paused')::bool THEN NOW() END, updated_at = NOW()
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id');
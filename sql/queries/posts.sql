@@ -0,0 +1,148 @@
+-- Posts are visible to a user when they belong to a followed feed or were
+-- starred by the user, as in browse and starred.
+
+-- name: GetPostByID :one
+SELECT * FROM posts
+WHERE id = sqlc.arg('id')
+    AND (EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg('user_id')
+    ) OR EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg('user_id')
+            AND post_states.starred_at IS NOT NULL
+    ));
+
+-- name: GetPostsByIDPrefix :many
+SELECT * FROM posts
+WHERE id::text LIKE sqlc.arg('prefix')::text || '%'
+    AND (EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg('user_id')
+    ) OR EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg('user_id')
+            AND post_states.starred_at IS NOT NULL
+    ))
+ORDER BY id
+LIMIT 2;
+
+-- name: GetPostByBrowseIndex :one
+SELECT posts.* FROM posts
+INNER JOIN post_states ON post_states.post_id = posts.id
+WHERE post_states.user_id = sqlc.arg('user_id')
+    AND post_states.browse_index = sqlc.arg('browse_index')::int;
+
+-- name: ClearBrowseIndexes :exec
+UPDATE post_states
+SET browse_index = NULL
+WHERE user_id = $1 AND browse_index IS NOT NULL;
+
+-- name: SetBrowseIndex :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, browse_index)
+VALUES (
+    sqlc.arg('user_id'),
+    sqlc.arg('post_id'),
+    NOW(),
+    NOW(),
+    sqlc.arg('browse_index')::int
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET browse_index = EXCLUDED.browse_index, updated_at = NOW();
+
+-- name: MarkPostRead :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW(),
+    NOW()
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = COALESCE(post_states.read_at, NOW()), updated_at = NOW();
+
+-- name: MarkPostOpened :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at, opened_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW(),
+    NOW(),
+    NOW()
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = COALESCE(post_states.read_at, NOW()),
+    opened_at = COALESCE(post_states.opened_at, NOW()),
+    updated_at = NOW();
+
+-- name: MarkPostUnread :execrows
+UPDATE post_states
+SET read_at = NULL, updated_at = NOW()
+WHERE user_id = $1 AND post_id = $2 AND read_at IS NOT NULL;
+
+-- name: MarkPostsRead :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at)
+SELECT feed_follows.user_id, posts.id, NOW(), NOW(), NOW()
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg('user_id')
+    AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url'))
+    AND (sqlc.narg('older_than')::timestamptz IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg('older_than'))
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = NOW(), updated_at = NOW()
+WHERE post_states.read_at IS NULL;
+
+-- name: StarPost :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, starred_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW(),
+    NOW()
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET starred_at = COALESCE(post_states.starred_at, NOW()), updated_at = NOW();
+
+-- name: UnstarPost :execrows
+UPDATE post_states
+SET starred_at = NULL, updated_at = NOW()
+WHERE user_id = $1 AND post_id = $2 AND starred_at IS NOT NULL;
+
+-- name: GetStarredPostsForUser :many
+SELECT posts.*, feeds.name AS feed_name, post_states.starred_at
+FROM post_states
+INNER JOIN posts ON posts.id = post_states.post_id
+LEFT JOIN feeds ON feeds.id = posts.feed_id
+WHERE post_states.user_id = $1 AND post_states.starred_at IS NOT NULL
+ORDER BY post_states.starred_at DESC;
+
+-- name: PrunePosts :execrows
+DELETE FROM posts
+WHERE COALESCE(posts.published_at, posts.created_at) < sqlc.arg('older_than')::timestamptz
+    AND NOT EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = posts.id AND post_states.starred_at IS NOT NULL
+    );
+
+-- name: PostExists :one
+SELECT EXISTS(SELECT 1 FROM posts WHERE url = $1)
+    OR EXISTS(SELECT 1 FROM post_links WHERE url = $1);
+
+-- name: SetPostURL :exec
+UPDATE posts
+SET url = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: AddPostLink :exec
+INSERT INTO post_links (url, post_id)
+SELECT sqlc.arg('link')::text, id FROM posts
+WHERE url = sqlc.arg('url')
+ON CONFLICT (url) DO NOTHING;
+
+-- name: DeletePost :exec
+DELETE FROM posts
+WHERE id = $1;
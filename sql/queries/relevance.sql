@@ -0,0 +1,58 @@
+-- name: DismissPost :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at, dismissed_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW(),
+    NOW(),
+    NOW()
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET dismissed_at = NOW(), read_at = COALESCE(post_states.read_at, NOW()), updated_at = NOW();
+
+-- Only what the user did trains the model: posts muted by their rules are
+-- left out, as learning from them would only reinforce the rules.
+-- name: GetTrainingPostsForUser :many
+SELECT
+    posts.title,
+    posts.description,
+    COALESCE(feeds.url, '')::text AS feed_url,
+    (post_states.starred_at IS NOT NULL)::bool AS starred,
+    (post_states.opened_at IS NOT NULL)::bool AS opened,
+    (post_states.dismissed_at IS NOT NULL)::bool AS dismissed
+FROM post_states
+INNER JOIN posts ON posts.id = post_states.post_id
+LEFT JOIN feeds ON feeds.id = posts.feed_id
+WHERE post_states.user_id = $1
+    AND (post_states.starred_at IS NOT NULL
+        OR post_states.opened_at IS NOT NULL
+        OR post_states.dismissed_at IS NOT NULL);
+
+-- name: UpsertRelevanceModel :exec
+INSERT INTO relevance_models (user_id, created_at, updated_at, model)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET model = EXCLUDED.model, updated_at = NOW();
+
+-- name: GetRelevanceModelsForFeed :many
+SELECT relevance_models.* FROM relevance_models
+INNER JOIN feed_follows ON feed_follows.user_id = relevance_models.user_id
+WHERE feed_follows.feed_id = $1;
+
+-- name: SetPostRelevance :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, relevance)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW(),
+    $3
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET relevance = EXCLUDED.relevance, updated_at = NOW();
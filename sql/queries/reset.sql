@@ -0,0 +1,79 @@
+-- name: CountAllData :one
+SELECT
+    (SELECT COUNT(*) FROM users) AS users,
+    (SELECT COUNT(*) FROM feeds) AS feeds,
+    (SELECT COUNT(*) FROM feed_follows) AS feed_follows,
+    (SELECT COUNT(*) FROM posts) AS posts,
+    (SELECT COUNT(*) FROM post_states) AS post_states,
+    (SELECT COUNT(*) FROM clusters) AS clusters,
+    (SELECT COUNT(*) FROM tags) AS tags,
+    (SELECT COUNT(*) FROM rules) AS rules;
+
+-- name: CountUserData :one
+SELECT
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = $1) AS feed_follows,
+    (SELECT COUNT(*) FROM post_states WHERE post_states.user_id = $1) AS post_states,
+    (SELECT COUNT(*) FROM tags WHERE tags.user_id = $1) AS tags,
+    (SELECT COUNT(*) FROM rules WHERE rules.user_id = $1) AS rules,
+    (SELECT COUNT(*) FROM feeds WHERE feeds.user_id = $1 AND NOT EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> $1
+    )) AS deleted_feeds,
+    (SELECT COUNT(*) FROM feeds WHERE feeds.user_id = $1 AND EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> $1
+    )) AS transferred_feeds;
+
+-- name: DeleteAllPosts :execrows
+DELETE FROM posts;
+
+-- name: DeleteAllFeeds :execrows
+DELETE FROM feeds;
+
+-- name: DeleteAllClusters :execrows
+DELETE FROM clusters;
+
+-- name: BackupAllData :one
+SELECT json_build_object(
+    'users', (SELECT COALESCE(json_agg(users), '[]') FROM users),
+    'feeds', (SELECT COALESCE(json_agg(feeds), '[]') FROM feeds),
+    'feed_follows', (SELECT COALESCE(json_agg(feed_follows), '[]') FROM feed_follows),
+    'tags', (SELECT COALESCE(json_agg(tags), '[]') FROM tags),
+    'feed_follow_tags', (SELECT COALESCE(json_agg(feed_follow_tags), '[]') FROM feed_follow_tags),
+    'rules', (SELECT COALESCE(json_agg(rules), '[]') FROM rules),
+    'relevance_models', (SELECT COALESCE(json_agg(relevance_models), '[]') FROM relevance_models),
+    'clusters', (SELECT COALESCE(json_agg(clusters), '[]') FROM clusters),
+    'posts', (SELECT COALESCE(json_agg(posts), '[]') FROM posts),
+    'post_links', (SELECT COALESCE(json_agg(post_links), '[]') FROM post_links),
+    'post_states', (SELECT COALESCE(json_agg(post_states), '[]') FROM post_states)
+)::text AS backup;
+
+-- The feeds of the user that nobody else follows are deleted with the user,
+-- so their posts are saved too with the post links, the post states of
+-- every user and the story clusters of those posts.
+-- name: BackupUserData :one
+WITH deleted_feeds AS (
+    SELECT feeds.id FROM feeds WHERE feeds.user_id = $1 AND NOT EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> $1
+    )
+), deleted_posts AS (
+    SELECT posts.* FROM posts WHERE posts.feed_id IN (SELECT id FROM deleted_feeds)
+)
+SELECT json_build_object(
+    'users', (SELECT COALESCE(json_agg(users), '[]') FROM users WHERE users.id = $1),
+    'feeds', (SELECT COALESCE(json_agg(feeds), '[]') FROM feeds WHERE feeds.user_id = $1),
+    'feed_follows', (SELECT COALESCE(json_agg(feed_follows), '[]') FROM feed_follows WHERE feed_follows.user_id = $1),
+    'tags', (SELECT COALESCE(json_agg(tags), '[]') FROM tags WHERE tags.user_id = $1),
+    'feed_follow_tags', (SELECT COALESCE(json_agg(feed_follow_tags), '[]') FROM feed_follow_tags
+        INNER JOIN tags ON tags.id = feed_follow_tags.tag_id WHERE tags.user_id = $1),
+    'rules', (SELECT COALESCE(json_agg(rules), '[]') FROM rules WHERE rules.user_id = $1),
+    'relevance_models', (SELECT COALESCE(json_agg(relevance_models), '[]') FROM relevance_models WHERE relevance_models.user_id = $1),
+    'posts', (SELECT COALESCE(json_agg(deleted_posts), '[]') FROM deleted_posts),
+    'post_links', (SELECT COALESCE(json_agg(post_links), '[]') FROM post_links
+        WHERE post_links.post_id IN (SELECT id FROM deleted_posts)),
+    'clusters', (SELECT COALESCE(json_agg(clusters), '[]') FROM clusters
+        WHERE clusters.id IN (SELECT cluster_id FROM deleted_posts)),
+    'post_states', (SELECT COALESCE(json_agg(post_states), '[]') FROM post_states
+        WHERE post_states.user_id = $1 OR post_states.post_id IN (SELECT id FROM deleted_posts))
+)::text AS backup;
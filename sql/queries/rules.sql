@@ -0,0 +1,57 @@
+-- name: CreateRule :one
+INSERT INTO rules (id, created_at, updated_at, user_id, field, match_type, pattern, action)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+) RETURNING *;
+
+-- name: GetRulesForUser :many
+SELECT * FROM rules WHERE user_id = $1 ORDER BY created_at;
+
+-- name: DeleteRule :execrows
+DELETE FROM rules WHERE id = $1 AND user_id = $2;
+
+-- name: GetRulesForFeed :many
+SELECT rules.*, COALESCE(feed_follows.title, feeds.name) AS feed_name, feeds.url AS feed_url
+FROM rules
+INNER JOIN feed_follows ON feed_follows.user_id = rules.user_id
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.feed_id = $1
+ORDER BY rules.user_id, rules.created_at;
+
+-- name: GetPostsToFilterForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.description,
+    posts.author,
+    posts.categories,
+    COALESCE(feed_follows.title, feeds.name) AS feed_name,
+    feeds.url AS feed_url
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1;
+
+-- name: SetPostFlags :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at, muted_at, highlighted_at)
+VALUES (
+    sqlc.arg('user_id'),
+    sqlc.arg('post_id'),
+    NOW(),
+    NOW(),
+    CASE WHEN sqlc.arg('mark_read')::bool THEN NOW() END,
+    CASE WHEN sqlc.arg('mute')::bool THEN NOW() END,
+    CASE WHEN sqlc.arg('highlight')::bool THEN NOW() END
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = COALESCE(post_states.read_at, EXCLUDED.read_at),
+    muted_at = COALESCE(post_states.muted_at, EXCLUDED.muted_at),
+    highlighted_at = COALESCE(post_states.highlighted_at, EXCLUDED.highlighted_at),
+    updated_at = NOW();
@@ -0,0 +1,25 @@
+-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    COALESCE(feed_follows.title, feeds.name) AS feed_name,
+    ts_rank(posts.search_vector, websearch_to_tsquery('english', sqlc.arg('query'))) AS rank,
+    ts_headline('english', posts.title, websearch_to_tsquery('english', sqlc.arg('query')), 'StartSel=<<<, StopSel=>>>, HighlightAll=true')::text AS title_highlight,
+    ts_headline('english', regexp_replace(posts.description, '<[^>]*>', ' ', 'g'), websearch_to_tsquery('english', sqlc.arg('query')), 'StartSel=<<<, StopSel=>>>, MaxWords=35, MinWords=15')::text AS snippet
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg('user_id')
+    AND posts.search_vector @@ websearch_to_tsquery('english', sqlc.arg('query'))
+    AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url'))
+    AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+        SELECT 1 FROM feed_follow_tags
+        INNER JOIN tags ON tags.id = feed_follow_tags.tag_id
+        WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = sqlc.narg('tag')
+    ))
+    AND (sqlc.narg('since')::timestamptz IS NULL OR COALESCE(posts.published_at, posts.created_at) >= sqlc.narg('since'))
+    AND (sqlc.narg('until')::timestamptz IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg('until'))
+ORDER BY rank DESC, posts.published_at DESC NULLS LAST
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,40 @@
+-- name: CreateTag :one
+INSERT INTO tags (id, created_at, updated_at, user_id, name)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    $2,
+    $3
+) RETURNING *;
+
+-- name: GetTagByName :one
+SELECT * FROM tags WHERE user_id = $1 AND name = $2;
+
+-- name: GetTagsForUser :many
+SELECT tags.*, COUNT(feed_follow_tags.feed_follow_id) AS feed_count
+FROM tags
+LEFT JOIN feed_follow_tags ON feed_follow_tags.tag_id = tags.id
+WHERE tags.user_id = $1
+GROUP BY tags.id
+ORDER BY tags.name;
+
+-- name: RenameTag :execrows
+UPDATE tags
+SET name = sqlc.arg('new_name'), updated_at = NOW()
+WHERE user_id = sqlc.arg('user_id') AND name = sqlc.arg('name');
+
+-- name: DeleteTag :execrows
+DELETE FROM tags WHERE user_id = $1 AND name = $2;
+
+-- name: AddFeedFollowTag :exec
+INSERT INTO feed_follow_tags (feed_follow_id, tag_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (feed_follow_id, tag_id) DO NOTHING;
+
+-- name: RemoveFeedFollowTag :execrows
+DELETE FROM feed_follow_tags WHERE feed_follow_id = $1 AND tag_id = $2;
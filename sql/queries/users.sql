@@ -30,7 +30,7 @@ VALUES (
 ) RETURNING *;
 
 -- name: GetFeeds :many
-SELECT feeds.name as rss_name, feeds.url as rss_url, users.name as user_name FROM feeds
+SELECT feeds.id, feeds.name as rss_name, feeds.url as rss_url, users.name as user_name, feeds.paused_at FROM feeds
 INNER JOIN users ON feeds.user_id = users.id;
 
 -- name: CreateFeedFollow :many
@@ -58,13 +58,39 @@ SELECT * FROM feeds WHERE url = $1;
 -- name: GetFeedFollowForUser :many
 SELECT 
     users.name AS user_name,
-    feeds.name AS feed_name,
+    COALESCE(feed_follows.title, feeds.name) AS feed_name,
     feeds.url AS feed_url,
-    feeds.last_fetched_at as last_fetched_at
+    feeds.last_fetched_at as last_fetched_at,
+    COALESCE(array_agg(tags.name ORDER BY tags.name) FILTER (WHERE tags.name IS NOT NULL), '{}')::text[] AS tags,
+    feed_follows.paused_at,
+    feed_follows.paused_until,
+    feeds.paused_at AS feed_paused_at
 FROM feed_follows
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
 INNER JOIN users ON users.id = feed_follows.user_id
-WHERE feed_follows.user_id = $1;
+LEFT JOIN feed_follow_tags ON feed_follow_tags.feed_follow_id = feed_follows.id
+LEFT JOIN tags ON tags.id = feed_follow_tags.tag_id
+WHERE feed_follows.user_id = sqlc.arg('user_id')
+    AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+        SELECT 1 FROM feed_follow_tags
+        INNER JOIN tags ON tags.id = feed_follow_tags.tag_id
+        WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = sqlc.narg('tag')
+    ))
+GROUP BY feed_follows.id, users.name, feeds.name, feeds.url, feeds.last_fetched_at, feeds.paused_at
+ORDER BY feeds.name;
+
+-- name: GetFeedFollowByURL :one
+SELECT feed_follows.* FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = $1 AND feeds.url = $2;
+
+-- name: SetFeedFollowTitle :execrows
+UPDATE feed_follows
+SET title = sqlc.narg('title'), updated_at = NOW()
+FROM feeds
+WHERE feeds.id = feed_follows.feed_id
+    AND feed_follows.user_id = sqlc.arg('user_id')
+    AND feeds.url = sqlc.arg('url');
 
 -- name: DeleteFeedFollow :exec
 DELETE FROM feed_follows USING feeds
@@ -76,11 +102,16 @@ SET last_fetched_at = NOW(), updated_at = NOW()
 WHERE id = $1;
 
 -- name: GetNextFeedToFetch :one
-SELECT id, url FROM feeds
+SELECT id, url, EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id AND feed_follows.fetch_full_content
+) AS fetch_full_content
+FROM feeds
+WHERE paused_at IS NULL
 ORDER BY last_fetched_at ASC NULLS FIRST;
 
 -- name: CreatePost :exec
-INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, simhash, raw_description)
 VALUES (
     $1,
     NOW(),
@@ -89,8 +120,41 @@ VALUES (
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
 );
 
+-- Clusters are collapsed over the posts left after filtering, so a cluster
+-- still shows up when its first post is read, muted or from another tag.
 -- name: GetPostsForUser :many
-SELECT * FROM posts;
+WITH visible AS (
+    SELECT posts.*, COALESCE(feed_follows.title, feeds.name) AS feed_name, post_states.read_at, post_states.highlighted_at, post_states.relevance, post_states.starred_at,
+        ROW_NUMBER() OVER (
+            PARTITION BY COALESCE(posts.cluster_id, posts.id)
+            ORDER BY posts.published_at ASC NULLS LAST, posts.id
+        ) AS cluster_rank
+    FROM posts
+    INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+    INNER JOIN feeds ON feeds.id = posts.feed_id
+    LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+    WHERE feed_follows.user_id = sqlc.arg('user_id')
+        AND (NOT sqlc.arg('unread_only')::bool OR post_states.read_at IS NULL)
+        AND (sqlc.arg('include_muted')::bool OR post_states.muted_at IS NULL)
+        AND (feed_follows.paused_at IS NULL OR feed_follows.paused_until <= NOW())
+        AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+            SELECT 1 FROM feed_follow_tags
+            INNER JOIN tags ON tags.id = feed_follow_tags.tag_id
+            WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = sqlc.narg('tag')
+        ))
+        AND (sqlc.narg('feed_url')::text IS NULL OR feeds.url = sqlc.narg('feed_url'))
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, search_vector, author, categories, simhash, cluster_id, raw_description, full_content, feed_name, read_at, highlighted_at, relevance, starred_at
+FROM visible
+WHERE NOT sqlc.arg('collapse_clusters')::bool OR cluster_rank = 1
+ORDER BY
+    CASE WHEN sqlc.arg('by_relevance')::bool THEN relevance END DESC NULLS LAST,
+    published_at DESC NULLS LAST
+LIMIT sqlc.arg('limit');
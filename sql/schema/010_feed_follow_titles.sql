@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE feed_follows
+ADD title TEXT DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN title;
@@ -0,0 +1,34 @@
+-- +goose Up
+ALTER TABLE posts
+ADD author TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE posts
+ADD categories TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE post_states
+ADD muted_at TIMESTAMP DEFAULT NULL;
+
+ALTER TABLE post_states
+ADD highlighted_at TIMESTAMP DEFAULT NULL;
+
+CREATE TABLE rules(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    field TEXT NOT NULL,
+    match_type TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL,
+    CHECK (field IN ('any', 'title', 'description', 'author', 'category', 'feed')),
+    CHECK (match_type IN ('keyword', 'regex')),
+    CHECK (action IN ('mute', 'highlight', 'mark-read')),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE rules;
+ALTER TABLE post_states DROP COLUMN highlighted_at;
+ALTER TABLE post_states DROP COLUMN muted_at;
+ALTER TABLE posts DROP COLUMN categories;
+ALTER TABLE posts DROP COLUMN author;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE post_states
+ADD dismissed_at TIMESTAMP DEFAULT NULL;
+
+ALTER TABLE post_states
+ADD relevance DOUBLE PRECISION DEFAULT NULL;
+
+CREATE TABLE relevance_models(
+    user_id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    model JSONB NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE relevance_models;
+ALTER TABLE post_states DROP COLUMN relevance;
+ALTER TABLE post_states DROP COLUMN dismissed_at;
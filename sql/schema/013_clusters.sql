@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE clusters(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    simhash BIGINT NOT NULL
+);
+
+ALTER TABLE posts
+ADD simhash BIGINT DEFAULT NULL;
+
+ALTER TABLE posts
+ADD cluster_id UUID REFERENCES clusters(id) ON DELETE SET NULL;
+
+CREATE INDEX posts_cluster_id_idx ON posts (cluster_id);
+CREATE INDEX posts_created_at_idx ON posts (created_at);
+
+-- +goose Down
+DROP INDEX posts_created_at_idx;
+DROP INDEX posts_cluster_id_idx;
+ALTER TABLE posts DROP COLUMN cluster_id;
+ALTER TABLE posts DROP COLUMN simhash;
+DROP TABLE clusters;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE post_states
+ADD browse_index INTEGER DEFAULT NULL;
+
+CREATE UNIQUE INDEX post_states_browse_index_idx ON post_states (user_id, browse_index);
+
+-- +goose Down
+DROP INDEX post_states_browse_index_idx;
+ALTER TABLE post_states DROP COLUMN browse_index;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE posts
+ADD raw_description TEXT DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN raw_description;
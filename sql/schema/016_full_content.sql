@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE posts
+ADD full_content TEXT DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN full_content;
+ALTER TABLE feeds DROP COLUMN fetch_full_content;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Deleting a user hands the feeds other users still follow to their oldest
+-- follower. The feeds nobody else follows are deleted by the cascade.
+-- +goose StatementBegin
+CREATE FUNCTION reassign_followed_feeds() RETURNS trigger AS $$
+BEGIN
+    UPDATE feeds
+    SET user_id = (
+        SELECT feed_follows.user_id FROM feed_follows
+        WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> OLD.id
+        ORDER BY feed_follows.created_at
+        LIMIT 1
+    ), updated_at = NOW()
+    WHERE feeds.user_id = OLD.id
+        AND EXISTS (
+            SELECT 1 FROM feed_follows
+            WHERE feed_follows.feed_id = feeds.id AND feed_follows.user_id <> OLD.id
+        );
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER users_reassign_followed_feeds
+BEFORE DELETE ON users
+FOR EACH ROW EXECUTE FUNCTION reassign_followed_feeds();
+
+-- +goose Down
+DROP TRIGGER users_reassign_followed_feeds ON users;
+DROP FUNCTION reassign_followed_feeds();
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD paused_at TIMESTAMP DEFAULT NULL;
+
+-- A follow is paused from paused_at until paused_until, or until it is
+-- resumed when paused_until is NULL.
+ALTER TABLE feed_follows
+ADD paused_at TIMESTAMP DEFAULT NULL;
+
+ALTER TABLE feed_follows
+ADD paused_until TIMESTAMP DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN paused_until;
+ALTER TABLE feed_follows DROP COLUMN paused_at;
+ALTER TABLE feeds DROP COLUMN paused_at;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- opened_at is only set when the user opens a post or marks that very post
+-- as read, so that bulk and rule-driven reads do not count as interest.
+ALTER TABLE post_states
+ADD opened_at TIMESTAMP DEFAULT NULL;
+
+-- +goose Down
+ALTER TABLE post_states DROP COLUMN opened_at;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Lets the candidates of a new post be read from the index alone.
+CREATE INDEX posts_cluster_candidates_idx ON posts (created_at)
+INCLUDE (simhash, cluster_id, feed_id)
+WHERE simhash IS NOT NULL;
+
+-- +goose Down
+DROP INDEX posts_cluster_candidates_idx;
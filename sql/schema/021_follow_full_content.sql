@@ -0,0 +1,25 @@
+-- +goose Up
+-- Full articles are fetched per reader: the aggregator downloads them for a
+-- feed as long as one of its followers asked for them.
+ALTER TABLE feed_follows
+ADD fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE feed_follows SET fetch_full_content = TRUE
+FROM feeds
+WHERE feeds.id = feed_follows.feed_id
+    AND feeds.user_id = feed_follows.user_id
+    AND feeds.fetch_full_content;
+
+ALTER TABLE feeds DROP COLUMN fetch_full_content;
+
+-- +goose Down
+ALTER TABLE feeds
+ADD fetch_full_content BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE feeds SET fetch_full_content = TRUE
+WHERE EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = feeds.id AND feed_follows.fetch_full_content
+);
+
+ALTER TABLE feed_follows DROP COLUMN fetch_full_content;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- The links given by feeds for posts stored under another URL, such as the
+-- canonical URL of their page, so that they are not ingested again.
+CREATE TABLE post_links(
+    url TEXT PRIMARY KEY,
+    post_id UUID NOT NULL,
+    FOREIGN KEY(post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_links;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Pauses are compared with NOW() in SQL and with the clock of the client in
+-- Go, so they are stored as instants rather than as local wall times.
+ALTER TABLE feed_follows ALTER COLUMN paused_at TYPE TIMESTAMPTZ;
+ALTER TABLE feed_follows ALTER COLUMN paused_until TYPE TIMESTAMPTZ;
+ALTER TABLE feeds ALTER COLUMN paused_at TYPE TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE feeds ALTER COLUMN paused_at TYPE TIMESTAMP;
+ALTER TABLE feed_follows ALTER COLUMN paused_until TYPE TIMESTAMP;
+ALTER TABLE feed_follows ALTER COLUMN paused_at TYPE TIMESTAMP;
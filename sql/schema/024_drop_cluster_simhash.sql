@@ -0,0 +1,8 @@
+-- +goose Up
+-- Posts are matched against the posts of a cluster, never against the hash
+-- of the post that started it.
+ALTER TABLE clusters DROP COLUMN simhash;
+
+-- +goose Down
+ALTER TABLE clusters
+ADD simhash BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,77 @@
+-- +goose Up
+-- The dates given to search, browse and prune are instants, so every stored
+-- time is one too, as the pauses became in 023.
+ALTER TABLE users ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE users ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE feeds ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE feeds ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+ALTER TABLE feeds ALTER COLUMN last_fetched_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE feed_follows ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE feed_follows ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE posts ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE posts ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+ALTER TABLE posts ALTER COLUMN published_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE post_states ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE post_states ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+ALTER TABLE post_states ALTER COLUMN read_at TYPE TIMESTAMPTZ;
+ALTER TABLE post_states ALTER COLUMN starred_at TYPE TIMESTAMPTZ;
+ALTER TABLE post_states ALTER COLUMN muted_at TYPE TIMESTAMPTZ;
+ALTER TABLE post_states ALTER COLUMN highlighted_at TYPE TIMESTAMPTZ;
+ALTER TABLE post_states ALTER COLUMN dismissed_at TYPE TIMESTAMPTZ;
+ALTER TABLE post_states ALTER COLUMN opened_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE tags ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE tags ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE feed_follow_tags ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE rules ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE rules ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE relevance_models ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE relevance_models ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE clusters ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE clusters ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE clusters ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE clusters ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE relevance_models ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE relevance_models ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE rules ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE rules ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE feed_follow_tags ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE tags ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE tags ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE post_states ALTER COLUMN opened_at TYPE TIMESTAMP;
+ALTER TABLE post_states ALTER COLUMN dismissed_at TYPE TIMESTAMP;
+ALTER TABLE post_states ALTER COLUMN highlighted_at TYPE TIMESTAMP;
+ALTER TABLE post_states ALTER COLUMN muted_at TYPE TIMESTAMP;
+ALTER TABLE post_states ALTER COLUMN starred_at TYPE TIMESTAMP;
+ALTER TABLE post_states ALTER COLUMN read_at TYPE TIMESTAMP;
+ALTER TABLE post_states ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE post_states ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE posts ALTER COLUMN published_at TYPE TIMESTAMP;
+ALTER TABLE posts ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE posts ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE feed_follows ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE feed_follows ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE feeds ALTER COLUMN last_fetched_at TYPE TIMESTAMP;
+ALTER TABLE feeds ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE feeds ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE users ALTER COLUMN updated_at TYPE TIMESTAMP;
+ALTER TABLE users ALTER COLUMN created_at TYPE TIMESTAMP;
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+func handlerStar(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 {
+		return errors.New("the command star expect one argument")
+	}
+
+	post, err := s.getPost(user, cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.StarPost(context.Background(),
+		database.StarPostParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the post could not be starred: %v", err)
+	}
+
+	fmt.Printf("Starred: %s\n", stripControl(post.Title))
+	return nil
+}
+
+func handlerUnstar(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 {
+		return errors.New("the command unstar expect one argument")
+	}
+
+	post, err := s.getPost(user, cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	count, err := s.db.UnstarPost(context.Background(),
+		database.UnstarPostParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the post could not be unstarred: %v", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("the post %s is not starred", cmd.args[1])
+	}
+
+	fmt.Printf("Unstarred: %s\n", stripControl(post.Title))
+	return nil
+}
+
+func handlerStarred(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 1 {
+		return errors.New("the command starred expect no argument")
+	}
+
+	posts, err := s.db.GetStarredPostsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("the starred posts could not be loaded: %v", err)
+	}
+
+	if len(posts) == 0 {
+		fmt.Println("There are no starred posts")
+		return nil
+	}
+
+	for _, post := range posts {
+		feedName := "(deleted feed)"
+		if post.FeedName.Valid {
+			feedName = post.FeedName.String
+		}
+
+		fmt.Printf("         ID: %s\n", shortID(post.ID))
+		fmt.Printf("      Title: %s\n", stripControl(post.Title))
+		fmt.Printf("       Feed: %s\n", stripControl(feedName))
+		fmt.Printf("    Starred: %s\n", post.StarredAt.Time.Format(time.RFC1123))
+		fmt.Printf("        URL: %s\n", stripControl(post.Url))
+		fmt.Printf("------------------\n\n")
+	}
+
+	return nil
+}
+
+// handlerPrune deletes the posts older than the given age. Posts starred by
+// any user are always kept.
+func handlerPrune(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 {
+		return errors.New("the command prune expect one argument")
+	}
+
+	age, err := parseAge(cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	count, err := s.db.PrunePosts(context.Background(), time.Now().Add(-age))
+	if err != nil {
+		return fmt.Errorf("the posts could not be pruned: %v", err)
+	}
+
+	fmt.Printf("%d posts pruned\n", count)
+	return nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+const tagUsage = "usage: tag list | tag create <name> | tag rename <old> <new> | tag delete <name> | tag add <name> <feed> | tag remove <name> <feed>"
+
+// handlerTag manages the tags (folders) used to organize the followed feeds.
+func handlerTag(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return errors.New(tagUsage)
+	}
+
+	subcommand, args := cmd.args[1], cmd.args[2:]
+
+	switch {
+	case subcommand == "list" && len(args) == 0:
+		return s.listTags(user)
+	case subcommand == "create" && len(args) == 1:
+		return s.createTag(user, args[0])
+	case subcommand == "rename" && len(args) == 2:
+		return s.renameTag(user, args[0], args[1])
+	case subcommand == "delete" && len(args) == 1:
+		return s.deleteTag(user, args[0])
+	case subcommand == "add" && len(args) == 2:
+		return s.tagFeed(user, args[0], args[1])
+	case subcommand == "remove" && len(args) == 2:
+		return s.untagFeed(user, args[0], args[1])
+	}
+
+	return errors.New(tagUsage)
+}
+
+func (s *state) listTags(user database.User) error {
+	tags, err := s.db.GetTagsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("the tags could not be loaded: %v", err)
+	}
+
+	if len(tags) == 0 {
+		fmt.Println("There are no tags, create one with: gator tag create <name>")
+		return nil
+	}
+
+	for _, tag := range tags {
+		fmt.Printf("* %s (%d feeds)\n", tag.Name, tag.FeedCount)
+	}
+	return nil
+}
+
+// getTag returns the tag of the user with the given name.
+func (s *state) getTag(user database.User, name string) (database.Tag, error) {
+	tag, err := s.db.GetTagByName(context.Background(),
+		database.GetTagByNameParams{
+			UserID: user.ID,
+			Name:   name,
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Tag{}, fmt.Errorf("the tag %s does not exist", name)
+	}
+	if err != nil {
+		return database.Tag{}, fmt.Errorf("the tag could not be loaded: %v", err)
+	}
+	return tag, nil
+}
+
+// getOrCreateTag returns the tag of the user with the given name, creating
+// it when it does not exist yet.
+func (s *state) getOrCreateTag(user database.User, name string) (database.Tag, error) {
+	tag, err := s.db.GetTagByName(context.Background(),
+		database.GetTagByNameParams{
+			UserID: user.ID,
+			Name:   name,
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.db.CreateTag(context.Background(),
+			database.CreateTagParams{
+				ID:     uuid.New(),
+				UserID: user.ID,
+				Name:   name,
+			},
+		)
+	}
+	return tag, err
+}
+
+func (s *state) createTag(user database.User, name string) error {
+	if _, err := s.getTag(user, name); err == nil {
+		return fmt.Errorf("the tag %s already exists", name)
+	}
+
+	_, err := s.db.CreateTag(context.Background(),
+		database.CreateTagParams{
+			ID:     uuid.New(),
+			UserID: user.ID,
+			Name:   name,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the tag could not be created: %v", err)
+	}
+
+	fmt.Printf("Tag created: %s\n", name)
+	return nil
+}
+
+func (s *state) renameTag(user database.User, oldName, newName string) error {
+	if _, err := s.getTag(user, newName); err == nil {
+		return fmt.Errorf("the tag %s already exists", newName)
+	}
+
+	count, err := s.db.RenameTag(context.Background(),
+		database.RenameTagParams{
+			NewName: newName,
+			UserID:  user.ID,
+			Name:    oldName,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the tag could not be renamed: %v", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("the tag %s does not exist", oldName)
+	}
+
+	fmt.Printf("Tag renamed: %s -> %s\n", oldName, newName)
+	return nil
+}
+
+func (s *state) deleteTag(user database.User, name string) error {
+	count, err := s.db.DeleteTag(context.Background(),
+		database.DeleteTagParams{
+			UserID: user.ID,
+			Name:   name,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the tag could not be deleted: %v", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("the tag %s does not exist", name)
+	}
+
+	fmt.Printf("Tag deleted: %s\n", name)
+	return nil
+}
+
+// getFeedFollow returns the follow of the user for the feed given by name,
+// ID or URL, with the URL of the feed. A feed matched only in part must be
+// confirmed.
+func (s *state) getFeedFollow(user database.User, ref string) (database.FeedFollow, string, error) {
+	candidates, err := s.followedFeedCandidates(user)
+	if err != nil {
+		return database.FeedFollow{}, "", err
+	}
+
+	feed, err := s.resolveFeedToChange(ref, candidates)
+	if err != nil {
+		return database.FeedFollow{}, "", err
+	}
+
+	feedFollow, err := s.db.GetFeedFollowByURL(context.Background(),
+		database.GetFeedFollowByURLParams{
+			UserID: user.ID,
+			Url:    feed.Url,
+		},
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.FeedFollow{}, "", fmt.Errorf("you are not following %s", feed.Url)
+	}
+	if err != nil {
+		return database.FeedFollow{}, "", fmt.Errorf("the feed follow could not be loaded: %v", err)
+	}
+	return feedFollow, feed.Url, nil
+}
+
+func (s *state) tagFeed(user database.User, name, ref string) error {
+	feedFollow, feedURL, err := s.getFeedFollow(user, ref)
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.getOrCreateTag(user, name)
+	if err != nil {
+		return fmt.Errorf("the tag could not be created: %v", err)
+	}
+
+	err = s.db.AddFeedFollowTag(context.Background(),
+		database.AddFeedFollowTagParams{
+			FeedFollowID: feedFollow.ID,
+			TagID:        tag.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the feed could not be tagged: %v", err)
+	}
+
+	fmt.Printf("Tagged %s with %s\n", feedURL, name)
+	return nil
+}
+
+func (s *state) untagFeed(user database.User, name, ref string) error {
+	feedFollow, feedURL, err := s.getFeedFollow(user, ref)
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.getTag(user, name)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.db.RemoveFeedFollowTag(context.Background(),
+		database.RemoveFeedFollowTagParams{
+			FeedFollowID: feedFollow.ID,
+			TagID:        tag.ID,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("the tag could not be removed: %v", err)
+	}
+
+	if count == 0 {
+		return fmt.Errorf("%s is not tagged with %s", feedURL, name)
+	}
+
+	fmt.Printf("Removed tag %s from %s\n", name, feedURL)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// rowTemplateFuncs are the helpers available to the --format templates.
+var rowTemplateFuncs = template.FuncMap{
+	"truncate":  truncate,
+	"ago":       ago,
+	"stripHTML": stripHTML,
+	"join":      strings.Join,
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+}
+
+// parseRowTemplate parses a --format template, printed once per row of the
+// listing, e.g. '{{.Feed}}: {{truncate 60 .Title}} ({{ago .PublishedAt}})'.
+func parseRowTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(rowTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format: %v", err)
+	}
+	return tmpl, nil
+}
+
+// truncate shortens the text to at most length characters, ending with an
+// ellipsis when something was cut.
+func truncate(length int, text string) string {
+	if length < 1 || utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	return string([]rune(text)[:length-1]) + "…"
+}
+
+// ago describes how long ago a time was, e.g. "5m ago" or "3d ago". Times
+// older than a month are printed as dates and missing times as "never".
+func ago(value any) (string, error) {
+	switch t := value.(type) {
+	case time.Time:
+		return formatAgo(time.Now(), t), nil
+	case *time.Time:
+		if t == nil {
+			return "never", nil
+		}
+		return formatAgo(time.Now(), *t), nil
+	default:
+		return "", fmt.Errorf("ago expects a time, got %T", value)
+	}
+}
+
+func formatAgo(now, t time.Time) string {
+	elapsed := now.Sub(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	case elapsed < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	default:
+		return t.Format(time.DateOnly)
+	}
+}
+
+// stripHTML removes the markup of a description and collapses its
+// whitespace, leaving a single line of text.
+func stripHTML(text string) string {
+	text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRowTemplate(t *testing.T) {
+	published := time.Now().Add(-3 * time.Hour)
+	rows := []PostRow{
+		{ShortID: "a1b2c3d4", Title: "A very long title about Go generics", Feed: "Go Blog", PublishedAt: &published},
+		{ShortID: "e5f6a7b8", Title: "Short", Feed: "Lobsters", Description: "<p>Hello &amp; <b>welcome</b></p>"},
+	}
+
+	cases := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "Fields",
+			format: "{{.ShortID}} {{.Feed}}",
+			want:   "a1b2c3d4 Go Blog\ne5f6a7b8 Lobsters\n",
+		},
+		{
+			name:   "Helpers",
+			format: "{{truncate 12 .Title}} ({{ago .PublishedAt}})",
+			want:   "A very long… (3h ago)\nShort (never)\n",
+		},
+		{
+			name:   "Strip HTML",
+			format: "[{{stripHTML .Description}}]",
+			want:   "[]\n[Hello & welcome]\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tmpl, err := parseRowTemplate(c.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := printRows(&buf, outputOptions{format: outputTemplate, template: tmpl}, rows); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != c.want {
+				t.Errorf("got %q, want %q", buf.String(), c.want)
+			}
+		})
+	}
+}
+
+func TestFormatAgo(t *testing.T) {
+	now := time.Date(2025, 5, 4, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{elapsed: 10 * time.Second, want: "just now"},
+		{elapsed: 5 * time.Minute, want: "5m ago"},
+		{elapsed: 26 * time.Hour, want: "1d ago"},
+		{elapsed: 60 * 24 * time.Hour, want: "2025-03-05"},
+	}
+
+	for _, c := range cases {
+		if got := formatAgo(now, now.Add(-c.elapsed)); got != c.want {
+			t.Errorf("formatAgo(%v) = %q, want %q", c.elapsed, got, c.want)
+		}
+	}
+}
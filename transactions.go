@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// errRollback is returned by a transaction function to discard its changes
+// without reporting an error, as done by the --dry-run modes.
+var errRollback = errors.New("the transaction was rolled back")
+
+// withTx runs fn with a copy of the state whose queries run in a single
+// transaction. The transaction is committed when fn succeeds and rolled back
+// otherwise.
+func (s *state) withTx(fn func(tx *state) error) error {
+	return s.withTxOptions(nil, fn)
+}
+
+// withTxOptions is withTx with a transaction started with opts, such as a
+// stricter isolation level.
+func (s *state) withTxOptions(opts *sql.TxOptions, fn func(tx *state) error) error {
+	tx, err := s.conn.BeginTx(context.Background(), opts)
+	if err != nil {
+		return fmt.Errorf("the transaction could not be started: %v", err)
+	}
+	defer tx.Rollback()
+
+	txState := *s
+	txState.db = s.db.WithTx(tx)
+
+	if err := fn(&txState); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("the transaction could not be committed: %v", err)
+	}
+	return nil
+}
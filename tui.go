@@ -0,0 +1,602 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+	"golang.org/x/term"
+)
+
+const (
+	tuiPostLimit       = 200
+	tuiRefreshInterval = 5 * time.Second
+	tuiHelp            = "tab pane  j/k move  enter read  m read/unread  s star  o open  u unread only  r refresh  q quit"
+
+	ansiReverse = "\033[7m"
+	ansiBold    = "\033[1m"
+	ansiReset   = "\033[0m"
+)
+
+type tuiPane int
+
+const (
+	paneFeeds tuiPane = iota
+	panePosts
+	panePreview
+)
+
+// tuiEntry is a line of the feed pane: every post, a tag shown as a folder or
+// a single feed.
+type tuiEntry struct {
+	label   string
+	tag     string
+	feedURL string
+}
+
+// tui is the full-screen reader started by the tui command.
+type tui struct {
+	s    *state
+	user database.User
+
+	entries []tuiEntry
+	posts   []database.GetPostsForUserRow
+	seen    map[uuid.UUID]bool
+
+	focus         tuiPane
+	feedCursor    int
+	postCursor    int
+	previewOffset int
+	unreadOnly    bool
+	status        string
+
+	width  int
+	height int
+}
+
+func handlerTui(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 1 {
+		return errors.New("the command tui does not expect arguments")
+	}
+
+	stdin := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdin) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return errors.New("the command tui must be run in a terminal")
+	}
+
+	t := &tui{s: s, user: user, seen: map[uuid.UUID]bool{}}
+	if err := t.reload(); err != nil {
+		return err
+	}
+	t.markSeen()
+	t.status = fmt.Sprintf("%d posts", len(t.posts))
+
+	oldState, err := term.MakeRaw(stdin)
+	if err != nil {
+		return fmt.Errorf("the terminal could not be set up: %v", err)
+	}
+	defer term.Restore(stdin, oldState)
+
+	// Switch to the alternate screen and hide the cursor while running.
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	keys := make(chan string)
+	go readKeys(keys)
+
+	refresh := time.NewTicker(tuiRefreshInterval)
+	defer refresh.Stop()
+	resize := time.NewTicker(250 * time.Millisecond)
+	defer resize.Stop()
+
+	t.draw()
+	for {
+		select {
+		case key, ok := <-keys:
+			if !ok || key == "q" || key == "ctrl-c" {
+				return nil
+			}
+			t.handleKey(key)
+		case <-refresh.C:
+			t.refresh()
+		case <-resize.C:
+			width, height, err := term.GetSize(int(os.Stdout.Fd()))
+			if err != nil || (width == t.width && height == t.height) {
+				continue
+			}
+		}
+		t.draw()
+	}
+}
+
+// readKeys sends the keys typed by the user until stdin is closed.
+func readKeys(keys chan<- string) {
+	defer close(keys)
+
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range decodeKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// decodeKeys translates the bytes read from a raw terminal into key names.
+func decodeKeys(buf []byte) []string {
+	sequences := map[string]string{
+		"\033[A":  "up",
+		"\033[B":  "down",
+		"\033[C":  "right",
+		"\033[D":  "left",
+		"\033[Z":  "backtab",
+		"\033[5~": "pgup",
+		"\033[6~": "pgdown",
+	}
+
+	keys := []string{}
+	for len(buf) > 0 {
+		matched := false
+		for seq, key := range sequences {
+			if strings.HasPrefix(string(buf), seq) {
+				keys = append(keys, key)
+				buf = buf[len(seq):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		switch buf[0] {
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case '\t':
+			keys = append(keys, "tab")
+		case 3:
+			keys = append(keys, "ctrl-c")
+		case '\033':
+			keys = append(keys, "esc")
+		default:
+			r, size := utf8.DecodeRune(buf)
+			keys = append(keys, string(r))
+			buf = buf[size:]
+			continue
+		}
+		buf = buf[1:]
+	}
+	return keys
+}
+
+// reload loads the feed pane and the posts of the selected entry.
+func (t *tui) reload() error {
+	follows, err := t.s.db.GetFeedFollowForUser(context.Background(),
+		database.GetFeedFollowForUserParams{UserID: t.user.ID},
+	)
+	if err != nil {
+		return fmt.Errorf("the followed feeds could not be loaded: %v", err)
+	}
+
+	t.entries = feedEntries(follows)
+	t.feedCursor = min(t.feedCursor, len(t.entries)-1)
+
+	return t.loadPosts()
+}
+
+// feedEntries lists every post first, then each tag as a folder holding its
+// feeds and finally the feeds without tags.
+func feedEntries(follows []database.GetFeedFollowForUserRow) []tuiEntry {
+	entries := []tuiEntry{{label: "All posts"}}
+
+	folders := map[string][]tuiEntry{}
+	tags := []string{}
+	untagged := []tuiEntry{}
+	now := time.Now()
+	for _, follow := range follows {
+		label := follow.FeedName
+		if isPaused(follow.PausedAt, follow.PausedUntil, now) || follow.FeedPausedAt.Valid {
+			label += " (paused)"
+		}
+
+		if len(follow.Tags) == 0 {
+			untagged = append(untagged, tuiEntry{label: label, feedURL: follow.FeedUrl})
+			continue
+		}
+		for _, tag := range follow.Tags {
+			if _, ok := folders[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			folders[tag] = append(folders[tag], tuiEntry{label: "  " + label, feedURL: follow.FeedUrl})
+		}
+	}
+
+	for _, tag := range tags {
+		entries = append(entries, tuiEntry{label: "[" + tag + "]", tag: tag})
+		entries = append(entries, folders[tag]...)
+	}
+	return append(entries, untagged...)
+}
+
+// loadPosts loads the posts of the selected entry, keeping the selected post
+// when it is still listed.
+func (t *tui) loadPosts() error {
+	entry := t.entries[t.feedCursor]
+	posts, err := t.s.db.GetPostsForUser(context.Background(),
+		database.GetPostsForUserParams{
+			UserID:     t.user.ID,
+			UnreadOnly: t.unreadOnly,
+			Tag:        sql.NullString{String: entry.tag, Valid: entry.tag != ""},
+			FeedUrl:    sql.NullString{String: entry.feedURL, Valid: entry.feedURL != ""},
+			Limit:      tuiPostLimit,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("posts could not be loaded from the database: %v", err)
+	}
+
+	selected, ok := t.selectedPost()
+	t.posts = posts
+	t.postCursor = 0
+	if ok {
+		for i, post := range posts {
+			if post.ID == selected.ID {
+				t.postCursor = i
+			}
+		}
+	}
+	return nil
+}
+
+// refresh reloads the posts and reports the ones inserted by the aggregator
+// since the last refresh.
+func (t *tui) refresh() {
+	if err := t.reload(); err != nil {
+		t.status = err.Error()
+		return
+	}
+
+	fresh := 0
+	for _, post := range t.posts {
+		if !t.seen[post.ID] {
+			fresh++
+		}
+	}
+	if fresh > 0 {
+		t.status = fmt.Sprintf("%d new posts", fresh)
+	}
+	t.markSeen()
+}
+
+func (t *tui) markSeen() {
+	for _, post := range t.posts {
+		t.seen[post.ID] = true
+	}
+}
+
+func (t *tui) selectedPost() (database.GetPostsForUserRow, bool) {
+	if t.postCursor < 0 || t.postCursor >= len(t.posts) {
+		return database.GetPostsForUserRow{}, false
+	}
+	return t.posts[t.postCursor], true
+}
+
+func (t *tui) handleKey(key string) {
+	t.status = ""
+
+	switch key {
+	case "tab", "l", "right":
+		t.focus = (t.focus + 1) % 3
+	case "backtab", "h", "left":
+		t.focus = (t.focus + 2) % 3
+	case "j", "down":
+		t.move(1)
+	case "k", "up":
+		t.move(-1)
+	case "pgdown":
+		t.move(t.height / 2)
+	case "pgup":
+		t.move(-t.height / 2)
+	case "enter":
+		if t.focus == paneFeeds {
+			t.focus = panePosts
+		} else if t.focus == panePosts {
+			t.markRead(true)
+			t.focus = panePreview
+		}
+	case "m":
+		post, ok := t.selectedPost()
+		if ok {
+			t.markRead(!post.ReadAt.Valid)
+		}
+	case "s":
+		t.toggleStar()
+	case "o":
+		t.open()
+	case "u":
+		t.unreadOnly = !t.unreadOnly
+		t.report(t.loadPosts())
+		t.markSeen()
+	case "r":
+		t.refresh()
+	}
+}
+
+func (t *tui) move(delta int) {
+	switch t.focus {
+	case paneFeeds:
+		t.feedCursor = clamp(t.feedCursor+delta, 0, len(t.entries)-1)
+		t.posts = nil
+		t.previewOffset = 0
+		t.report(t.loadPosts())
+		t.markSeen()
+	case panePosts:
+		t.postCursor = clamp(t.postCursor+delta, 0, len(t.posts)-1)
+		t.previewOffset = 0
+	case panePreview:
+		t.previewOffset = max(t.previewOffset+delta, 0)
+	}
+}
+
+func (t *tui) markRead(read bool) {
+	post, ok := t.selectedPost()
+	if !ok {
+		return
+	}
+
+	params := database.MarkPostReadParams{UserID: t.user.ID, PostID: post.ID}
+	if read {
+		t.report(t.s.db.MarkPostRead(context.Background(), params))
+	} else {
+		_, err := t.s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams(params))
+		t.report(err)
+	}
+	t.report(t.loadPosts())
+}
+
+func (t *tui) toggleStar() {
+	post, ok := t.selectedPost()
+	if !ok {
+		return
+	}
+
+	if post.StarredAt.Valid {
+		_, err := t.s.db.UnstarPost(context.Background(),
+			database.UnstarPostParams{UserID: t.user.ID, PostID: post.ID},
+		)
+		t.report(err)
+	} else {
+		t.report(t.s.db.StarPost(context.Background(),
+			database.StarPostParams{UserID: t.user.ID, PostID: post.ID},
+		))
+	}
+	t.report(t.loadPosts())
+}
+
+func (t *tui) open() {
+	post, ok := t.selectedPost()
+	if !ok {
+		return
+	}
+
+	if err := openURL(post.Url); err != nil {
+		t.status = fmt.Sprintf("the post could not be opened: %v", err)
+		return
+	}
+	t.report(t.s.db.MarkPostOpened(context.Background(),
+		database.MarkPostOpenedParams{UserID: t.user.ID, PostID: post.ID},
+	))
+	t.report(t.loadPosts())
+	t.status = "Opened: " + post.Title
+}
+
+// report shows an error of an action in the status bar.
+func (t *tui) report(err error) {
+	if err != nil {
+		t.status = err.Error()
+	}
+}
+
+// draw renders the whole screen: the feed pane on the left, the post list
+// and the preview of the selected post on the right.
+func (t *tui) draw() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	t.width, t.height = width, height
+
+	leftWidth := clamp(width/4, 16, 40)
+	rightWidth := max(width-leftWidth-1, 1)
+	bodyRows := max(height-2, 2)
+	listRows := max(bodyRows/2, 1)
+	previewRows := max(bodyRows-listRows-1, 0)
+
+	left := t.feedLines(leftWidth, bodyRows)
+	right := t.postLines(rightWidth, listRows)
+	right = append(right, strings.Repeat("─", rightWidth))
+	right = append(right, t.previewLines(rightWidth, previewRows)...)
+
+	var screen strings.Builder
+	screen.WriteString("\033[H")
+	screen.WriteString(ansiReverse + fitWidth(" gator — "+t.user.Name+t.filterLabel(), width) + ansiReset + "\r\n")
+	for i := 0; i < bodyRows; i++ {
+		screen.WriteString(left[i] + "│" + right[i] + "\r\n")
+	}
+
+	status := t.status
+	if status == "" {
+		status = tuiHelp
+	}
+	screen.WriteString(ansiReverse + fitWidth(" "+status, width) + ansiReset)
+	fmt.Print(screen.String())
+}
+
+func (t *tui) filterLabel() string {
+	if t.unreadOnly {
+		return " (unread only)"
+	}
+	return ""
+}
+
+func (t *tui) feedLines(width, rows int) []string {
+	labels := make([]string, len(t.entries))
+	for i, entry := range t.entries {
+		labels[i] = entry.label
+	}
+	return listLines(labels, t.feedCursor, t.focus == paneFeeds, width, rows)
+}
+
+func (t *tui) postLines(width, rows int) []string {
+	labels := make([]string, len(t.posts))
+	for i, post := range t.posts {
+		mark := "●"
+		if post.ReadAt.Valid {
+			mark = " "
+		}
+		if post.StarredAt.Valid {
+			mark += "★"
+		} else {
+			mark += " "
+		}
+
+		date := "      "
+		if post.PublishedAt.Valid {
+			date = post.PublishedAt.Time.Format("Jan 02")
+		}
+		labels[i] = fmt.Sprintf("%s %s  %s — %s", mark, date, post.Title, post.FeedName)
+	}
+
+	if len(labels) == 0 {
+		labels = []string{"There are no posts to show"}
+		return listLines(labels, -1, false, width, rows)
+	}
+	return listLines(labels, t.postCursor, t.focus == panePosts, width, rows)
+}
+
+func (t *tui) previewLines(width, rows int) []string {
+	lines := []string{}
+	if post, ok := t.selectedPost(); ok {
+		lines = append(lines, ansiBold+fitWidth(post.Title, width)+ansiReset)
+		meta := post.FeedName + " · " + shortID(post.ID)
+		if post.PublishedAt.Valid {
+			meta += " · " + post.PublishedAt.Time.Format(time.RFC1123)
+		}
+		lines = append(lines, fitWidth(meta, width), fitWidth(post.Url, width), fitWidth("", width))
+
+		content := post.Description
+		if post.FullContent.Valid {
+			content = post.FullContent.String
+		}
+		for _, line := range renderHTML(content, renderOptions{width: width, links: linksFootnotes}) {
+			lines = append(lines, fitWidth(line, width))
+		}
+	}
+
+	t.previewOffset = clamp(t.previewOffset, 0, max(len(lines)-rows, 0))
+	lines = lines[t.previewOffset:]
+
+	visible := make([]string, rows)
+	for i := range visible {
+		if i < len(lines) {
+			visible[i] = lines[i]
+		} else {
+			visible[i] = fitWidth("", width)
+		}
+	}
+	return visible
+}
+
+// listLines renders a scrolled list keeping the cursor visible. The cursor
+// line is shown in reverse video when the list has the focus.
+func listLines(labels []string, cursor int, focused bool, width, rows int) []string {
+	start := scrollOffset(cursor, len(labels), rows)
+
+	lines := make([]string, rows)
+	for i := range lines {
+		index := start + i
+		if index >= len(labels) {
+			lines[i] = fitWidth("", width)
+			continue
+		}
+
+		line := fitWidth(" "+labels[index], width)
+		if index == cursor {
+			if focused {
+				line = ansiReverse + line + ansiReset
+			} else {
+				line = ansiBold + line + ansiReset
+			}
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// scrollOffset returns the first item shown so that the cursor stays in the
+// middle of the visible rows whenever possible.
+func scrollOffset(cursor, count, rows int) int {
+	if count <= rows || cursor < 0 {
+		return 0
+	}
+	return clamp(cursor-rows/2, 0, count-rows)
+}
+
+// fitWidth truncates or pads the text to exactly width columns. Control
+// characters are replaced by spaces so that they cannot move the cursor or
+// start an escape sequence.
+func fitWidth(text string, width int) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text)
+
+	length := utf8.RuneCountInString(text)
+	if length > width {
+		runes := []rune(text)
+		if width < 1 {
+			return ""
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-length)
+}
+
+// wrapText splits the text into lines of at most width columns at word
+// boundaries.
+func wrapText(text string, width int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func clamp(value, low, high int) int {
+	return max(low, min(value, high))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Letters", input: "jk", want: []string{"j", "k"}},
+		{name: "Arrows", input: "\033[A\033[B", want: []string{"up", "down"}},
+		{name: "Enter and tab", input: "\r\t", want: []string{"enter", "tab"}},
+		{name: "Shift tab", input: "\033[Z", want: []string{"backtab"}},
+		{name: "Escape", input: "\033", want: []string{"esc"}},
+		{name: "Control C", input: "\x03", want: []string{"ctrl-c"}},
+		{name: "Unicode", input: "é", want: []string{"é"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := decodeKeys([]byte(c.input)); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("decodeKeys(%q) = %q, want %q", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+func TestFeedEntries(t *testing.T) {
+	follows := []database.GetFeedFollowForUserRow{
+		{FeedName: "Blog", FeedUrl: "https://blog.example.com/rss"},
+		{FeedName: "Go", FeedUrl: "https://go.dev/blog/feed.atom", Tags: []string{"dev", "news"}},
+		{FeedName: "Lobsters", FeedUrl: "https://lobste.rs/rss", Tags: []string{"news"}},
+		{FeedName: "Ars", FeedUrl: "https://arstechnica.com/feed", Tags: []string{"news, tech"}},
+	}
+
+	want := []tuiEntry{
+		{label: "All posts"},
+		{label: "[dev]", tag: "dev"},
+		{label: "  Go", feedURL: "https://go.dev/blog/feed.atom"},
+		{label: "[news]", tag: "news"},
+		{label: "  Go", feedURL: "https://go.dev/blog/feed.atom"},
+		{label: "  Lobsters", feedURL: "https://lobste.rs/rss"},
+		{label: "[news, tech]", tag: "news, tech"},
+		{label: "  Ars", feedURL: "https://arstechnica.com/feed"},
+		{label: "Blog", feedURL: "https://blog.example.com/rss"},
+	}
+
+	if got := feedEntries(follows); !reflect.DeepEqual(got, want) {
+		t.Errorf("feedEntries() = %v, want %v", got, want)
+	}
+}
+
+func TestFitWidth(t *testing.T) {
+	cases := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{text: "abc", width: 5, want: "abc  "},
+		{text: "abcdef", width: 4, want: "abc…"},
+		{text: "día", width: 3, want: "día"},
+		{text: "a\tb", width: 3, want: "a b"},
+		{text: "abc", width: 0, want: ""},
+		{text: "a\x1b]8;;x", width: 7, want: "a ]8;;x"},
+		{text: "a\u009bb\x7fc", width: 5, want: "a b c"},
+	}
+
+	for _, c := range cases {
+		if got := fitWidth(c.text, c.width); got != c.want {
+			t.Errorf("fitWidth(%q, %d) = %q, want %q", c.text, c.width, got, c.want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("the quick brown fox jumps over the lazy dog", 10)
+	want := []string{"the quick", "brown fox", "jumps over", "the lazy", "dog"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapText() = %q, want %q", got, want)
+	}
+}
+
+func TestScrollOffset(t *testing.T) {
+	cases := []struct {
+		cursor, count, rows, want int
+	}{
+		{cursor: 0, count: 5, rows: 10, want: 0},
+		{cursor: 2, count: 20, rows: 10, want: 0},
+		{cursor: 10, count: 20, rows: 10, want: 5},
+		{cursor: 19, count: 20, rows: 10, want: 10},
+	}
+
+	for _, c := range cases {
+		if got := scrollOffset(c.cursor, c.count, c.rows); got != c.want {
+			t.Errorf("scrollOffset(%d, %d, %d) = %d, want %d", c.cursor, c.count, c.rows, got, c.want)
+		}
+	}
+}
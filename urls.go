@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/vladimirck/gator/internal/database"
+)
+
+// defaultTrackingParams are stripped from feed and post URLs unless the
+// tracking_params setting of the config replaces them.
+var defaultTrackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"dclid",
+	"msclkid",
+	"yclid",
+	"mc_cid",
+	"mc_eid",
+	"_hsenc",
+	"_hsmi",
+	"igshid",
+}
+
+// normalizeURL canonicalizes a web URL so that the same page is stored once:
+// the scheme and host are lowercased, default ports, fragments, trailing
+// slashes and tracking parameters are removed.
+func normalizeURL(raw string, trackingParams []string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("the URL %s is not valid: %v", raw, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("the URL %s is not an http or https URL", raw)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("the URL %s has no host", raw)
+	}
+
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if u.Path == "" {
+		u.Path = "/"
+	} else if len(u.Path) > 1 {
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+	}
+
+	u.RawQuery = stripTrackingParams(u.RawQuery, trackingParams)
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+// stripTrackingParams removes the tracking parameters of a query and keeps
+// the order and encoding of the others.
+func stripTrackingParams(query string, trackingParams []string) string {
+	if query == "" {
+		return ""
+	}
+
+	kept := []string{}
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil {
+			key = name
+		}
+		if !isTrackingParam(key, trackingParams) {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func isTrackingParam(key string, trackingParams []string) bool {
+	key = strings.ToLower(key)
+	for _, param := range trackingParams {
+		param = strings.ToLower(param)
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == param {
+			return true
+		}
+	}
+	return false
+}
+
+// otherScheme returns the URL with http and https swapped. Both usually
+// serve the same feed.
+func otherScheme(u string) string {
+	if rest, ok := strings.CutPrefix(u, "https://"); ok {
+		return "http://" + rest
+	}
+	if rest, ok := strings.CutPrefix(u, "http://"); ok {
+		return "https://" + rest
+	}
+	return ""
+}
+
+func (s *state) trackingParams() []string {
+	if s.cfg.TrackingParams != nil {
+		return s.cfg.TrackingParams
+	}
+	return defaultTrackingParams
+}
+
+// normalizeURL normalizes a URL with the tracking parameters of the config.
+func (s *state) normalizeURL(raw string) (string, error) {
+	return normalizeURL(raw, s.trackingParams())
+}
+
+// normalizeLink normalizes the URL of a feed or a post, keeping it as it is
+// when it is not a web URL.
+func (s *state) normalizeLink(raw string) string {
+	normalized, err := s.normalizeURL(raw)
+	if err != nil {
+		return raw
+	}
+	return normalized
+}
+
+// findFeedByURL loads a feed by its normalized URL, the same URL with the
+// other scheme or, for feeds stored before normalization, the URL as given.
+// It returns sql.ErrNoRows when none is found.
+func (s *state) findFeedByURL(raw string) (database.Feed, error) {
+	candidates := []string{}
+	if normalized, err := s.normalizeURL(raw); err == nil {
+		candidates = append(candidates, normalized, otherScheme(normalized))
+	}
+	candidates = append(candidates, raw)
+
+	for _, candidate := range candidates {
+		feed, err := s.db.GetFeedByURL(context.Background(), candidate)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		return feed, err
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	cases := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{name: "Already normal", url: "https://example.com/feed.xml", want: "https://example.com/feed.xml"},
+		{name: "Scheme and host case", url: "HTTPS://Example.COM/Feed.xml", want: "https://example.com/Feed.xml"},
+		{name: "Default http port", url: "http://example.com:80/rss", want: "http://example.com/rss"},
+		{name: "Default https port", url: "https://example.com:443/rss", want: "https://example.com/rss"},
+		{name: "Other port", url: "https://example.com:8443/rss", want: "https://example.com:8443/rss"},
+		{name: "Trailing slash", url: "https://example.com/blog/feed/", want: "https://example.com/blog/feed"},
+		{name: "Empty path", url: "https://example.com", want: "https://example.com/"},
+		{name: "Fragment", url: "https://example.com/post#comments", want: "https://example.com/post"},
+		{name: "Tracking parameters", url: "https://example.com/post?utm_source=rss&id=3&UTM_Medium=feed&fbclid=x", want: "https://example.com/post?id=3"},
+		{name: "Only tracking parameters", url: "https://example.com/post?utm_campaign=a", want: "https://example.com/post"},
+		{name: "Parameter order kept", url: "https://example.com/?b=2&a=1", want: "https://example.com/?b=2&a=1"},
+		{name: "IPv6 host", url: "http://[::1]:80/feed", want: "http://[::1]/feed"},
+		{name: "Spaces", url: "  https://example.com/feed  ", want: "https://example.com/feed"},
+		{name: "Not a web URL", url: "ftp://example.com/feed", wantErr: true},
+		{name: "No host", url: "https:///feed", wantErr: true},
+		{name: "Relative", url: "example.com/feed", wantErr: true},
+	}
+
+	for _, c := range cases {
+		got, err := normalizeURL(c.url, defaultTrackingParams)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: normalizeURL() error = %v, wantErr %v", c.name, err, c.wantErr)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s: normalizeURL() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeURLConfiguredParams(t *testing.T) {
+	got, err := normalizeURL("https://example.com/post?utm_source=rss&ref=home&src_id=2", []string{"ref", "src_*"})
+	if err != nil {
+		t.Fatalf("normalizeURL() error = %v", err)
+	}
+	if want := "https://example.com/post?utm_source=rss"; got != want {
+		t.Errorf("normalizeURL() = %q, want %q", got, want)
+	}
+}
+
+func TestOtherScheme(t *testing.T) {
+	cases := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://example.com/feed", want: "http://example.com/feed"},
+		{url: "http://example.com/feed", want: "https://example.com/feed"},
+		{url: "ftp://example.com/feed", want: ""},
+	}
+
+	for _, c := range cases {
+		if got := otherScheme(c.url); got != c.want {
+			t.Errorf("otherScheme(%q) = %q, want %q", c.url, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/vladimirck/gator/internal/database"
+)
+
+// getUser loads a user by name.
+func (s *state) getUser(name string) (database.User, error) {
+	user, err := s.db.GetUserByName(context.Background(), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, fmt.Errorf("the user %s was not found", name)
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("the user could not be loaded: %v", err)
+	}
+	return user, nil
+}
+
+// parseUserCommand parses the optional user name and the --yes flag of the
+// commands acting on a whole user, which default to the current user.
+func (s *state) parseUserCommand(cmd command, current database.User) (database.User, bool, error) {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return database.User{}, false, err
+	}
+
+	if len(args) > 1 {
+		return database.User{}, false, fmt.Errorf("the command %s expect zero or one argument: [user] [--yes]", cmd.name)
+	}
+
+	if len(args) == 0 {
+		return current, *yes, nil
+	}
+
+	user, err := s.getUser(args[0])
+	return user, *yes, err
+}
+
+// confirmUserAction asks for confirmation of a command on a whole user. A
+// command on another user than the current one needs their name typed back.
+func confirmUserAction(question string, user, current database.User, yes bool) error {
+	if user.ID == current.ID {
+		return confirm(question, yes)
+	}
+	return confirmName(question, user.Name, yes)
+}
+
+func handlerDeleteUser(s *state, cmd command, current database.User) error {
+	user, yes, err := s.parseUserCommand(cmd, current)
+	if err != nil {
+		return err
+	}
+
+	question := fmt.Sprintf("Delete the user %s with their follows, tags, rules and read states?", user.Name)
+	if err := confirmUserAction(question, user, current, yes); err != nil {
+		return err
+	}
+
+	if err := s.deleteUser(user); err != nil {
+		return err
+	}
+	return s.logoutDeleted(user.Name)
+}
+
+// deleteUser deletes a user with their data. The caller logs them out with
+// logoutDeleted once the deletion is committed.
+func (s *state) deleteUser(user database.User) error {
+	if _, err := s.db.DeleteUser(context.Background(), user.ID); err != nil {
+		return fmt.Errorf("the user could not be deleted: %v", err)
+	}
+
+	fmt.Printf("User deleted: %s\n", user.Name)
+	return nil
+}
+
+// logoutDeleted clears the current user of the config when it was deleted.
+func (s *state) logoutDeleted(name string) error {
+	if name != s.cfg.CurrentUserName {
+		return nil
+	}
+	if err := s.cfg.SetUser(""); err != nil {
+		return err
+	}
+	fmt.Println("Nobody is logged in now, use login or register")
+	return nil
+}
+
+func handlerRenameUser(s *state, cmd command, current database.User) error {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+
+	args, err := parseFlags(fs, cmd.args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 && len(args) != 2 {
+		return errors.New("the command renameuser expect one or two arguments: [user] <new-name> [--yes]")
+	}
+
+	user, newName := current, args[0]
+	if len(args) == 2 {
+		newName = args[1]
+		if user, err = s.getUser(args[0]); err != nil {
+			return err
+		}
+	}
+
+	if user.ID != current.ID {
+		question := fmt.Sprintf("Rename the user %s to %s?", user.Name, newName)
+		if err := confirmName(question, user.Name, *yes); err != nil {
+			return err
+		}
+	}
+
+	name := user.Name
+
+	user, err = s.db.RenameUser(context.Background(),
+		database.RenameUserParams{
+			NewName: newName,
+			ID:      user.ID,
+		},
+	)
+
+	if isUniqueViolation(err) {
+		return fmt.Errorf("the user %s already exists", newName)
+	}
+	if err != nil {
+		return fmt.Errorf("the user could not be renamed: %v", err)
+	}
+
+	if user.ID == current.ID {
+		if err := s.cfg.SetUser(user.Name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("User renamed: %s -> %s\n", name, user.Name)
+	return nil
+}
+
+// handlerWipeUser deletes the data of a user but keeps the account and the
+// feeds, which other users may follow.
+func handlerWipeUser(s *state, cmd command, current database.User) error {
+	user, yes, err := s.parseUserCommand(cmd, current)
+	if err != nil {
+		return err
+	}
+
+	question := fmt.Sprintf("Delete the follows, tags, rules, read states and relevance model of %s?", user.Name)
+	if err := confirmUserAction(question, user, current, yes); err != nil {
+		return err
+	}
+
+	counts := make([]int64, 5)
+	err = s.withTx(func(tx *state) error {
+		for i, wipe := range []func(context.Context, uuid.UUID) (int64, error){
+			tx.db.DeleteFeedFollowsForUser,
+			tx.db.DeleteTagsForUser,
+			tx.db.DeleteRulesForUser,
+			tx.db.DeletePostStatesForUser,
+			tx.db.DeleteRelevanceModelForUser,
+		} {
+			count, err := wipe(context.Background(), user.ID)
+			if err != nil {
+				return fmt.Errorf("the data of %s could not be deleted: %v", user.Name, err)
+			}
+			counts[i] = count
+		}
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Data of %s deleted:\n", user.Name)
+	fmt.Printf("      Follows: %d\n", counts[0])
+	fmt.Printf("         Tags: %d\n", counts[1])
+	fmt.Printf("        Rules: %d\n", counts[2])
+	fmt.Printf("  Post states: %d\n", counts[3])
+	fmt.Printf("    Relevance: %d\n", counts[4])
+	return nil
+}